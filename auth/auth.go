@@ -2,36 +2,201 @@ package auth
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
 type AuthType string
 
 const (
-	SSHAgentAuth AuthType = "ssh-agent"
-	DefaultAuth  AuthType = "default"
+	SSHAgentAuth         AuthType = "ssh-agent"
+	DefaultAuth          AuthType = "default"
+	TokenAuth            AuthType = "token"
+	SSHKeyAuth           AuthType = "ssh-key"
+	CredentialHelperAuth AuthType = "credential-helper"
+	NetrcAuth            AuthType = "netrc"
+	AutoAuth             AuthType = "auto"
 )
 
+// DefaultSSHUser is the user used for ssh auth types if none is set
+const DefaultSSHUser = "git"
+
+// DefaultTokenUsername is the username sent with token auth if none is set,
+// most git hosts accept any non empty username with a personal access token
+const DefaultTokenUsername = "git"
+
+// Options stores the settings used by the different auth types
+type Options struct {
+	// TokenEnv is the name of the environment variable holding the token for token auth
+	TokenEnv string `mapstructure:"token_env" yaml:"token_env" toml:"token_env"`
+	// TokenFile is the path of a file holding the token for token auth
+	TokenFile string `mapstructure:"token_file" yaml:"token_file" toml:"token_file"`
+	// TokenUsername is the username sent with the token for token auth
+	TokenUsername string `mapstructure:"token_username" yaml:"token_username" toml:"token_username"`
+	// SSHUser is the user for the ssh auth types
+	SSHUser string `mapstructure:"ssh_user" yaml:"ssh_user" toml:"ssh_user"`
+	// SSHKeyFile is the path of the private key for ssh-key auth
+	SSHKeyFile string `mapstructure:"ssh_key_file" yaml:"ssh_key_file" toml:"ssh_key_file"`
+	// SSHKeyPassphraseEnv is the name of the environment variable holding the
+	// passphrase of SSHKeyFile
+	SSHKeyPassphraseEnv string `mapstructure:"ssh_key_passphrase_env" yaml:"ssh_key_passphrase_env" toml:"ssh_key_passphrase_env"`
+	// SSHKeyPassphraseFile is the path of a file holding the passphrase of SSHKeyFile
+	SSHKeyPassphraseFile string `mapstructure:"ssh_key_passphrase_file" yaml:"ssh_key_passphrase_file" toml:"ssh_key_passphrase_file"`
+	// SSHKnownHostsFile is the path of the known_hosts file used to verify ssh host keys
+	SSHKnownHostsFile string `mapstructure:"ssh_known_hosts_file" yaml:"ssh_known_hosts_file" toml:"ssh_known_hosts_file"`
+	// SSHHostKeyFingerprints are the pinned SHA256 fingerprints of accepted ssh host keys
+	SSHHostKeyFingerprints []string `mapstructure:"ssh_host_key_fingerprints" yaml:"ssh_host_key_fingerprints" toml:"ssh_host_key_fingerprints"`
+	// SSHInsecureIgnoreHostKey disables ssh host key verification
+	SSHInsecureIgnoreHostKey bool `mapstructure:"ssh_insecure_ignore_host_key" yaml:"ssh_insecure_ignore_host_key" toml:"ssh_insecure_ignore_host_key"`
+	// NetrcFile is the path of the netrc file for netrc auth, defaults to
+	// $NETRC or ~/.netrc
+	NetrcFile string `mapstructure:"netrc_file" yaml:"netrc_file" toml:"netrc_file"`
+	// URL is the url of the remote used by the auto, credential-helper and
+	// netrc auth types, it is set by the Resolver and can't be configured
+	URL string `mapstructure:"-" yaml:"-" toml:"-"`
+}
+
 type Auth struct {
 	auth transport.AuthMethod
 }
 
-func NewAuth(authType AuthType) (*Auth, error) {
-	var err error
-	a := &Auth{}
-	switch authType {
-	case SSHAgentAuth:
-		a.auth, err = ssh.NewSSHAgentAuth("git")
-	case DefaultAuth:
-		a.auth = nil
-	default:
-		err = fmt.Errorf("auth type %s not supported. valid values: %s, %s", authType, SSHAgentAuth, DefaultAuth)
+func init() {
+	Register(DefaultAuth, func(Options) (transport.AuthMethod, error) { return nil, nil })
+	Register(SSHAgentAuth, newSSHAgentAuth)
+	Register(SSHKeyAuth, newSSHKeyAuth)
+	Register(TokenAuth, newTokenAuth)
+	Register(CredentialHelperAuth, newCredentialHelperAuth)
+	Register(NetrcAuth, newNetrcAuth)
+	Register(AutoAuth, newAutoAuth)
+}
+
+// NewAuth creates the auth method of authType using the Factory registered
+// for it
+func NewAuth(authType AuthType, opts Options) (*Auth, error) {
+	factory, ok := lookup(authType)
+	if !ok {
+		return &Auth{}, unsupportedAuthTypeError(authType)
 	}
-	return a, err
+	a, err := factory(opts)
+	return &Auth{auth: a}, err
 }
 
 func (a Auth) Get() transport.AuthMethod {
 	return a.auth
 }
+
+// Approve reports that the remote accepted the credentials if the auth
+// method is a Reporter
+func (a Auth) Approve() error {
+	if r, ok := a.auth.(Reporter); ok {
+		return r.Approve()
+	}
+	return nil
+}
+
+// Reject reports that the remote rejected the credentials if the auth method
+// is a Reporter
+func (a Auth) Reject() error {
+	if r, ok := a.auth.(Reporter); ok {
+		return r.Reject()
+	}
+	return nil
+}
+
+// newSSHAgentAuth returns ssh auth using the keys of the running ssh-agent
+func newSSHAgentAuth(opts Options) (transport.AuthMethod, error) {
+	a, err := ssh.NewSSHAgentAuth(opts.sshUser())
+	if err != nil {
+		return a, err
+	}
+	return a, setHostKeyCallback(a, opts)
+}
+
+// newTokenAuth returns http basic auth using a personal access token read from
+// the environment variable TokenEnv or the file TokenFile. Errors never
+// contain the token
+func newTokenAuth(opts Options) (transport.AuthMethod, error) {
+	token, err := readSecret("token", opts.TokenEnv, opts.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	username := opts.TokenUsername
+	if username == "" {
+		username = DefaultTokenUsername
+	}
+	return basicAuth(username, token), nil
+}
+
+// basicAuth returns http basic auth with the secret as password,
+// http.BasicAuth masks the password when printed so it never ends up in logs
+func basicAuth(username, secret string) *http.BasicAuth {
+	return &http.BasicAuth{Username: username, Password: secret}
+}
+
+// newSSHKeyAuth returns ssh public key auth using the private key SSHKeyFile,
+// the passphrase of the key is read from SSHKeyPassphraseEnv or
+// SSHKeyPassphraseFile if either of them is set
+func newSSHKeyAuth(opts Options) (transport.AuthMethod, error) {
+	if opts.SSHKeyFile == "" {
+		return nil, fmt.Errorf("ssh_key_file must be set")
+	}
+	var passphrase string
+	if opts.SSHKeyPassphraseEnv != "" || opts.SSHKeyPassphraseFile != "" {
+		var err error
+		passphrase, err = readSecret("ssh_key_passphrase", opts.SSHKeyPassphraseEnv, opts.SSHKeyPassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	a, err := ssh.NewPublicKeysFromFile(opts.sshUser(), opts.SSHKeyFile, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ssh key %s: %w", opts.SSHKeyFile, unwrapPathError(err))
+	}
+	return a, setHostKeyCallback(a, opts)
+}
+
+// sshUser returns SSHUser or DefaultSSHUser if it is not set
+func (o Options) sshUser() string {
+	if o.SSHUser == "" {
+		return DefaultSSHUser
+	}
+	return o.SSHUser
+}
+
+// readSecret reads a secret from the environment variable env if set or else
+// from file. Errors only mention the names of the variable and file
+func readSecret(name, env, file string) (string, error) {
+	var secret string
+	switch {
+	case env != "":
+		secret = os.Getenv(env)
+		if secret == "" {
+			return "", fmt.Errorf("%s environment variable %s is not set or empty", name, env)
+		}
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s file %s: %w", name, file, unwrapPathError(err))
+		}
+		secret = strings.TrimSpace(string(b))
+		if secret == "" {
+			return "", fmt.Errorf("%s file %s is empty", name, file)
+		}
+	default:
+		return "", fmt.Errorf("%s_env or %s_file must be set", name, name)
+	}
+	return secret, nil
+}
+
+// unwrapPathError returns the underlying error of a *os.PathError so the path
+// is not repeated in error messages
+func unwrapPathError(err error) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return pe.Err
+	}
+	return err
+}
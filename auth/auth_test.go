@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +17,7 @@ import (
 func TestNewAuth(t *testing.T) {
 
 	t.Run("default auth", func(t *testing.T) {
-		a, err := NewAuth(DefaultAuth)
+		a, err := NewAuth(DefaultAuth, Options{})
 		assert.Nil(t, err)
 		assert.Nil(t, a.auth)
 	})
@@ -32,7 +34,7 @@ func TestNewAuth(t *testing.T) {
 		}
 		os.Unsetenv(sockVar)
 		os.Unsetenv(pidVar)
-		a, err := NewAuth(SSHAgentAuth)
+		a, err := NewAuth(SSHAgentAuth, Options{})
 		assert.EqualError(t, err, `error creating SSH agent: "SSH agent requested but SSH_AUTH_SOCK not-specified"`)
 		_, ok := a.auth.(*ssh.PublicKeysCallback)
 		assert.True(t, ok, "expected returned auth type to be of ssh-agent type")
@@ -51,17 +53,83 @@ func TestNewAuth(t *testing.T) {
 		}
 
 		// Ensure ssh-agent authenticator initialization works with ssh-agent running
-		a, err = NewAuth(SSHAgentAuth)
+		a, err = NewAuth(SSHAgentAuth, Options{})
 		assert.Nil(t, err)
 		_, ok = a.auth.(*ssh.PublicKeysCallback)
 		assert.True(t, ok, "expected returned auth type to be of ssh-agent type")
 
 	})
 
+	t.Run("token auth", func(t *testing.T) {
+		secret := "s3cr3t-t0ken"
+		t.Setenv("TEST_GROOMBA_TOKEN", secret)
+		a, err := NewAuth(TokenAuth, Options{TokenEnv: "TEST_GROOMBA_TOKEN"})
+		assert.Nil(t, err)
+		basic, ok := a.auth.(*http.BasicAuth)
+		assert.True(t, ok, "expected returned auth type to be of http basic auth type")
+		assert.Equal(t, DefaultTokenUsername, basic.Username)
+		assert.Equal(t, secret, basic.Password)
+		assert.NotContains(t, fmt.Sprintf("%s %v", basic, basic), secret)
+
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		err = os.WriteFile(tokenFile, []byte(secret+"\n"), 0600)
+		assert.Nil(t, err)
+		a, err = NewAuth(TokenAuth, Options{TokenFile: tokenFile, TokenUsername: "oauth2"})
+		assert.Nil(t, err)
+		basic, ok = a.auth.(*http.BasicAuth)
+		assert.True(t, ok, "expected returned auth type to be of http basic auth type")
+		assert.Equal(t, "oauth2", basic.Username)
+		assert.Equal(t, secret, basic.Password)
+
+		_, err = NewAuth(TokenAuth, Options{})
+		assert.EqualError(t, err, "token_env or token_file must be set")
+		_, err = NewAuth(TokenAuth, Options{TokenEnv: "TEST_GROOMBA_TOKEN_UNSET"})
+		assert.EqualError(t, err, "token environment variable TEST_GROOMBA_TOKEN_UNSET is not set or empty")
+		_, err = NewAuth(TokenAuth, Options{TokenFile: tokenFile + ".missing"})
+		assert.EqualError(t, err, fmt.Sprintf("failed to read token file %s.missing: no such file or directory", tokenFile))
+	})
+
+	t.Run("ssh-key auth", func(t *testing.T) {
+		dir := t.TempDir()
+		keyFile := filepath.Join(dir, "id_ed25519")
+		passphrase := "s3cr3t-passphrase"
+		err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", passphrase, "-f", keyFile).Run()
+		assert.Nil(t, err, "failed to generate ssh key for test")
+
+		t.Setenv("TEST_GROOMBA_PASSPHRASE", passphrase)
+		a, err := NewAuth(SSHKeyAuth, Options{SSHKeyFile: keyFile, SSHKeyPassphraseEnv: "TEST_GROOMBA_PASSPHRASE", SSHUser: "deploy"})
+		assert.Nil(t, err)
+		keys, ok := a.auth.(*ssh.PublicKeys)
+		assert.True(t, ok, "expected returned auth type to be of ssh public keys type")
+		if ok {
+			assert.Equal(t, "deploy", keys.User)
+		}
+
+		passphraseFile := filepath.Join(dir, "passphrase")
+		err = os.WriteFile(passphraseFile, []byte(passphrase+"\n"), 0600)
+		assert.Nil(t, err)
+		a, err = NewAuth(SSHKeyAuth, Options{SSHKeyFile: keyFile, SSHKeyPassphraseFile: passphraseFile})
+		assert.Nil(t, err)
+		keys, ok = a.auth.(*ssh.PublicKeys)
+		assert.True(t, ok, "expected returned auth type to be of ssh public keys type")
+		if ok {
+			assert.Equal(t, DefaultSSHUser, keys.User)
+		}
+
+		_, err = NewAuth(SSHKeyAuth, Options{})
+		assert.EqualError(t, err, "ssh_key_file must be set")
+		_, err = NewAuth(SSHKeyAuth, Options{SSHKeyFile: keyFile + ".missing"})
+		assert.EqualError(t, err, fmt.Sprintf("failed to load ssh key %s.missing: no such file or directory", keyFile))
+		_, err = NewAuth(SSHKeyAuth, Options{SSHKeyFile: keyFile})
+		assert.NotNil(t, err, "expected error loading encrypted key without passphrase")
+		_, err = NewAuth(SSHKeyAuth, Options{SSHKeyFile: keyFile, SSHKeyPassphraseEnv: "TEST_GROOMBA_PASSPHRASE_UNSET"})
+		assert.EqualError(t, err, "ssh_key_passphrase environment variable TEST_GROOMBA_PASSPHRASE_UNSET is not set or empty")
+	})
+
 	t.Run("default auth", func(t *testing.T) {
 		u := AuthType("undefined-auth-type")
-		a, err := NewAuth(u)
-		assert.EqualError(t, err, fmt.Sprintf("auth type %s not supported. valid values: %s, %s", u, SSHAgentAuth, DefaultAuth))
+		a, err := NewAuth(u, Options{})
+		assert.EqualError(t, err, fmt.Sprintf("auth type %s not supported. valid values: auto, credential-helper, default, netrc, ssh-agent, ssh-key, token", u))
 		assert.Nil(t, a.auth)
 	})
 
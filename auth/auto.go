@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// ciProvider describes how to find the job token of a CI environment
+type ciProvider struct {
+	name string
+	// detectEnv is set to true in jobs of the provider
+	detectEnv string
+	// tokenEnvs are the variables that can hold the job token, in order of preference
+	tokenEnvs []string
+	// username is sent with the job token
+	username string
+	// serverEnv holds the url of the git server the job token is valid for,
+	// defaultServer is used if it is not set
+	serverEnv     string
+	defaultServer string
+}
+
+// ciProviders are the CI environments detected by auto auth. Gitea is checked
+// before GitHub since Gitea Actions also sets GITHUB_ACTIONS
+var ciProviders = []ciProvider{
+	{
+		name:      "Gitea Actions",
+		detectEnv: "GITEA_ACTIONS",
+		tokenEnvs: []string{"GITEA_TOKEN", "GITHUB_TOKEN"},
+		username:  "x-access-token",
+		serverEnv: "GITHUB_SERVER_URL",
+	},
+	{
+		name:          "GitHub Actions",
+		detectEnv:     "GITHUB_ACTIONS",
+		tokenEnvs:     []string{"GITHUB_TOKEN", "GH_TOKEN"},
+		username:      "x-access-token",
+		serverEnv:     "GITHUB_SERVER_URL",
+		defaultServer: "https://github.com",
+	},
+	{
+		name:      "GitLab CI",
+		detectEnv: "GITLAB_CI",
+		tokenEnvs: []string{"CI_JOB_TOKEN"},
+		username:  "gitlab-ci-token",
+		serverEnv: "CI_SERVER_URL",
+	},
+}
+
+// newAutoAuth picks the credentials for the remote URL from the environment.
+// For http remotes the job token of a detected CI provider is used if the
+// remote is hosted on the git server of the job. Otherwise ssh remotes use
+// ssh-agent if it is running and all other remotes use default auth
+func newAutoAuth(opts Options) (transport.AuthMethod, error) {
+	if _, err := httpURL(AutoAuth, opts.URL); err == nil {
+		for _, p := range ciProviders {
+			if a, ok := p.auth(opts.URL); ok {
+				return a, nil
+			}
+		}
+	} else if isSSHURL(opts.URL) && os.Getenv("SSH_AUTH_SOCK") != "" {
+		log.Infof("auto auth: using ssh-agent for %s", redactURL(opts.URL))
+		return newSSHAgentAuth(opts)
+	}
+	log.Infof("auto auth: using default auth for %s", redactURL(opts.URL))
+	return nil, nil
+}
+
+// auth returns http basic auth with the job token of p if running in a job of
+// p and rawURL is on the git server of the job, so job tokens are never sent
+// to other hosts
+func (p ciProvider) auth(rawURL string) (transport.AuthMethod, bool) {
+	if os.Getenv(p.detectEnv) != "true" {
+		return nil, false
+	}
+	server := os.Getenv(p.serverEnv)
+	if server == "" {
+		server = p.defaultServer
+	}
+	if server == "" || !strings.EqualFold(urlHost(server), urlHost(rawURL)) {
+		log.Infof("auto auth: detected %s but %s is not on its git server %s", p.name, redactURL(rawURL), server)
+		return nil, false
+	}
+	for _, env := range p.tokenEnvs {
+		if token := os.Getenv(env); token != "" {
+			log.Infof("auto auth: using the %s token from %s for %s", p.name, env, redactURL(rawURL))
+			return basicAuth(p.username, token), true
+		}
+	}
+	log.Infof("auto auth: detected %s but none of %s is set", p.name, strings.Join(p.tokenEnvs, ", "))
+	return nil, false
+}
+
+// isSSHURL returns true if rawURL is an ssh url or uses the scp-like syntax
+// user@host:path
+func isSSHURL(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "ssh://") || strings.HasPrefix(rawURL, "git+ssh://") {
+		return true
+	}
+	return !strings.Contains(rawURL, "://") && urlHost(rawURL) != ""
+}
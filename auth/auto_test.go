@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+// clearCIEnv unsets the variables auto auth looks at so tests behave the same
+// when they run in CI
+func clearCIEnv(t *testing.T) {
+	for _, env := range []string{
+		"GITEA_ACTIONS", "GITEA_TOKEN",
+		"GITHUB_ACTIONS", "GITHUB_TOKEN", "GH_TOKEN", "GITHUB_SERVER_URL",
+		"GITLAB_CI", "CI_JOB_TOKEN", "CI_SERVER_URL",
+		"SSH_AUTH_SOCK",
+	} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestAutoAuth(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		env      map[string]string
+		url      string
+		expected transport.AuthMethod
+	}{
+		{
+			name:     "no CI should use default auth",
+			url:      "https://github.com/avbm/groomba.git",
+			expected: nil,
+		},
+		{
+			name:     "GitHub Actions should use GITHUB_TOKEN",
+			env:      map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_TOKEN": "gh-token"},
+			url:      "https://github.com/avbm/groomba.git",
+			expected: &http.BasicAuth{Username: "x-access-token", Password: "gh-token"},
+		},
+		{
+			name:     "GitHub Actions should fall back to GH_TOKEN",
+			env:      map[string]string{"GITHUB_ACTIONS": "true", "GH_TOKEN": "gh-token"},
+			url:      "https://github.com/avbm/groomba.git",
+			expected: &http.BasicAuth{Username: "x-access-token", Password: "gh-token"},
+		},
+		{
+			name:     "GitHub Actions token should not be sent to other hosts",
+			env:      map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_TOKEN": "gh-token"},
+			url:      "https://gitlab.com/avbm/groomba.git",
+			expected: nil,
+		},
+		{
+			name:     "GitHub Enterprise should use GITHUB_SERVER_URL",
+			env:      map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_TOKEN": "ghe-token", "GITHUB_SERVER_URL": "https://ghe.example.com"},
+			url:      "https://ghe.example.com/avbm/groomba.git",
+			expected: &http.BasicAuth{Username: "x-access-token", Password: "ghe-token"},
+		},
+		{
+			name:     "GitHub Actions without token should use default auth",
+			env:      map[string]string{"GITHUB_ACTIONS": "true"},
+			url:      "https://github.com/avbm/groomba.git",
+			expected: nil,
+		},
+		{
+			name:     "Gitea Actions should use GITEA_TOKEN",
+			env:      map[string]string{"GITEA_ACTIONS": "true", "GITHUB_ACTIONS": "true", "GITEA_TOKEN": "gitea-token", "GITHUB_TOKEN": "other", "GITHUB_SERVER_URL": "https://gitea.example.com"},
+			url:      "https://gitea.example.com/avbm/groomba.git",
+			expected: &http.BasicAuth{Username: "x-access-token", Password: "gitea-token"},
+		},
+		{
+			name:     "Gitea Actions without server url should use default auth",
+			env:      map[string]string{"GITEA_ACTIONS": "true", "GITEA_TOKEN": "gitea-token"},
+			url:      "https://gitea.example.com/avbm/groomba.git",
+			expected: nil,
+		},
+		{
+			name:     "GitLab CI should use CI_JOB_TOKEN",
+			env:      map[string]string{"GITLAB_CI": "true", "CI_JOB_TOKEN": "job-token", "CI_SERVER_URL": "https://gitlab.example.com"},
+			url:      "https://gitlab.example.com/avbm/groomba.git",
+			expected: &http.BasicAuth{Username: "gitlab-ci-token", Password: "job-token"},
+		},
+		{
+			name:     "CI tokens should not be used for ssh remotes",
+			env:      map[string]string{"GITLAB_CI": "true", "CI_JOB_TOKEN": "job-token", "CI_SERVER_URL": "https://gitlab.example.com"},
+			url:      "git@gitlab.example.com:avbm/groomba.git",
+			expected: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clearCIEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			a, err := NewAuth(AutoAuth, Options{URL: tc.url})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, a.Get())
+		})
+	}
+
+	t.Run("ssh remotes should use ssh-agent if it is running", func(t *testing.T) {
+		clearCIEnv(t)
+		t.Setenv("SSH_AUTH_SOCK", "/nonexistent/agent.sock")
+		a, err := NewAuth(AutoAuth, Options{URL: "ssh://git@github.com/avbm/groomba.git"})
+		assert.NotNil(t, err, "expected error connecting to missing ssh-agent")
+		_, ok := a.Get().(*ssh.PublicKeysCallback)
+		assert.True(t, ok, "expected returned auth type to be of ssh-agent type")
+	})
+
+	t.Run("local remotes should use default auth", func(t *testing.T) {
+		clearCIEnv(t)
+		t.Setenv("SSH_AUTH_SOCK", "/nonexistent/agent.sock")
+		a, err := NewAuth(AutoAuth, Options{URL: "/tmp/groomba"})
+		assert.Nil(t, err)
+		assert.Nil(t, a.Get())
+	})
+}
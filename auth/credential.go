@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// credential is http basic auth using the username and password obtained
+// from the git credential helpers for url, it reports back to the helpers if
+// the remote accepted them
+type credential struct {
+	*http.BasicAuth
+	url string
+}
+
+// newCredentialHelperAuth returns http basic auth using the credentials the
+// configured git credential.helper returns for the remote URL. Errors never
+// contain the password
+func newCredentialHelperAuth(opts Options) (transport.AuthMethod, error) {
+	if _, err := httpURL(CredentialHelperAuth, opts.URL); err != nil {
+		return nil, err
+	}
+
+	c := &credential{BasicAuth: basicAuth("", ""), url: opts.URL}
+	out, err := c.run("fill")
+	if err != nil {
+		return nil, err
+	}
+	c.Username, c.Password = out["username"], out["password"]
+	if c.Password == "" {
+		return nil, fmt.Errorf("git credential helper returned no password for %s", redactURL(opts.URL))
+	}
+	return c, nil
+}
+
+// Approve tells the credential helpers that the credentials were accepted so
+// they can be stored
+func (c *credential) Approve() error {
+	_, err := c.run("approve")
+	return err
+}
+
+// Reject tells the credential helpers that the credentials were rejected so
+// they can be erased
+func (c *credential) Reject() error {
+	_, err := c.run("reject")
+	return err
+}
+
+// run runs git credential action speaking the credential helper protocol on
+// stdin and stdout. Terminal prompts are disabled so a missing credential is
+// an error instead of blocking a worker
+func (c *credential) run(action string) (map[string]string, error) {
+	var in bytes.Buffer
+	fmt.Fprintf(&in, "url=%s\n", c.url)
+	if action != "fill" {
+		fmt.Fprintf(&in, "username=%s\npassword=%s\n", c.Username, c.Password)
+	}
+	in.WriteString("\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "credential", action)
+	cmd.Stdin = &in
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git credential %s failed for %s: %s: %s", action, redactURL(c.url), err, strings.TrimSpace(stderr.String()))
+	}
+
+	out := map[string]string{}
+	s := bufio.NewScanner(&stdout)
+	for s.Scan() {
+		if k, v, ok := strings.Cut(s.Text(), "="); ok {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// httpURL parses the remote url rawURL for authType which only supports http
+// and https remotes
+func httpURL(authType AuthType, rawURL string) (*url.URL, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("%s auth requires the url of the remote", authType)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%s auth requires an http or https remote, got %s", authType, redactURL(rawURL))
+	}
+	return u, nil
+}
+
+// redactURL returns u without the password of its user info so it is safe to
+// print
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return parsed.Redacted()
+}
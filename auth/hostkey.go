@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// hostKeyCallback returns the callback verifying ssh host keys based on the
+// host key options. When KnownHostsFile and HostKeyFingerprints are both set
+// a host key has to pass both checks. It returns nil if no option is set so
+// the go-git default of checking the user's known_hosts is used
+func hostKeyCallback(opts Options) (gossh.HostKeyCallback, error) {
+	if opts.SSHInsecureIgnoreHostKey {
+		log.Warn("!!! ssh host key verification is DISABLED since ssh_insecure_ignore_host_key=true, connections are open to man-in-the-middle attacks !!!")
+		return gossh.InsecureIgnoreHostKey(), nil
+	}
+
+	callbacks := []gossh.HostKeyCallback{}
+	if opts.SSHKnownHostsFile != "" {
+		// go-git hides the reason a known_hosts file can't be used so check it first
+		if _, err := os.Stat(opts.SSHKnownHostsFile); err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts file %s: %w", opts.SSHKnownHostsFile, unwrapPathError(err))
+		}
+		cb, err := ssh.NewKnownHostsCallback(opts.SSHKnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts file %s: %w", opts.SSHKnownHostsFile, unwrapPathError(err))
+		}
+		callbacks = append(callbacks, cb)
+	}
+	if len(opts.SSHHostKeyFingerprints) > 0 {
+		callbacks = append(callbacks, fingerprintCallback(opts.SSHHostKeyFingerprints))
+	}
+
+	if len(callbacks) == 0 {
+		warnMissingKnownHosts()
+		return nil, nil
+	}
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		for _, cb := range callbacks {
+			if err := cb(hostname, remote, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// fingerprintCallback returns a callback accepting only host keys whose
+// SHA256 fingerprint, as printed by ssh-keygen -l, is one of fingerprints
+func fingerprintCallback(fingerprints []string) gossh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		fp := gossh.FingerprintSHA256(key)
+		for _, f := range fingerprints {
+			if strings.TrimSpace(f) == fp {
+				return nil
+			}
+		}
+		return fmt.Errorf("ssh host key of %s with fingerprint %s does not match any of ssh_host_key_fingerprints", hostname, fp)
+	}
+}
+
+// warnMissingKnownHosts logs a hint when none of the known_hosts files that
+// go-git checks by default exist, since connecting fails with an unclear
+// error in that case
+func warnMissingKnownHosts() {
+	files := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS"))
+	if len(files) == 0 {
+		if home, err := os.UserHomeDir(); err == nil {
+			files = append(files, filepath.Join(home, ".ssh", "known_hosts"))
+		}
+		files = append(files, "/etc/ssh/ssh_known_hosts")
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			return
+		}
+	}
+	log.Warnf("no known_hosts file found at %s, ssh connections will fail unless ssh_known_hosts_file or ssh_host_key_fingerprints is set", strings.Join(files, ", "))
+}
+
+// setHostKeyCallback sets the host key callback of ssh based auth methods
+func setHostKeyCallback(a transport.AuthMethod, opts Options) error {
+	cb, err := hostKeyCallback(opts)
+	if err != nil || cb == nil {
+		return err
+	}
+	switch s := a.(type) {
+	case *ssh.PublicKeys:
+		s.HostKeyCallback = cb
+	case *ssh.PublicKeysCallback:
+		s.HostKeyCallback = cb
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// generateHostKey generates an ssh key pair in dir and returns its public key
+func generateHostKey(t *testing.T, dir, name string) gossh.PublicKey {
+	keyFile := filepath.Join(dir, name)
+	err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyFile).Run()
+	assert.Nil(t, err, "failed to generate ssh key for test")
+	b, err := os.ReadFile(keyFile + ".pub")
+	assert.Nil(t, err)
+	key, _, _, _, err := gossh.ParseAuthorizedKey(b)
+	assert.Nil(t, err)
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	dir := t.TempDir()
+	hostKey := generateHostKey(t, dir, "host_key")
+	otherKey := generateHostKey(t, dir, "other_key")
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	err := os.WriteFile(knownHosts, []byte("git.example.com "+string(gossh.MarshalAuthorizedKey(hostKey))), 0600)
+	assert.Nil(t, err)
+
+	t.Run("no options should use go-git defaults", func(t *testing.T) {
+		cb, err := hostKeyCallback(Options{})
+		assert.Nil(t, err)
+		assert.Nil(t, cb)
+	})
+
+	t.Run("insecure mode should accept any host key", func(t *testing.T) {
+		a := assert.New(t)
+		cb, err := hostKeyCallback(Options{SSHInsecureIgnoreHostKey: true, SSHKnownHostsFile: knownHosts})
+		a.Nil(err)
+		a.Nil(cb("other.example.com:22", addr, otherKey))
+	})
+
+	t.Run("known_hosts file should verify host keys", func(t *testing.T) {
+		a := assert.New(t)
+		cb, err := hostKeyCallback(Options{SSHKnownHostsFile: knownHosts})
+		a.Nil(err)
+		a.Nil(cb("git.example.com:22", addr, hostKey))
+		a.NotNil(cb("git.example.com:22", addr, otherKey))
+		a.NotNil(cb("other.example.com:22", addr, hostKey))
+	})
+
+	t.Run("missing known_hosts file should return error", func(t *testing.T) {
+		_, err := hostKeyCallback(Options{SSHKnownHostsFile: knownHosts + ".missing"})
+		assert.EqualError(t, err, fmt.Sprintf("failed to load known_hosts file %s.missing: no such file or directory", knownHosts))
+	})
+
+	t.Run("pinned fingerprints should verify host keys", func(t *testing.T) {
+		a := assert.New(t)
+		cb, err := hostKeyCallback(Options{SSHHostKeyFingerprints: []string{"SHA256:unknown", gossh.FingerprintSHA256(hostKey)}})
+		a.Nil(err)
+		a.Nil(cb("git.example.com:22", addr, hostKey))
+		err = cb("git.example.com:22", addr, otherKey)
+		a.EqualError(err, fmt.Sprintf("ssh host key of git.example.com:22 with fingerprint %s does not match any of ssh_host_key_fingerprints", gossh.FingerprintSHA256(otherKey)))
+	})
+
+	t.Run("known_hosts file and pinned fingerprints should both be checked", func(t *testing.T) {
+		a := assert.New(t)
+		cb, err := hostKeyCallback(Options{SSHKnownHostsFile: knownHosts, SSHHostKeyFingerprints: []string{gossh.FingerprintSHA256(otherKey)}})
+		a.Nil(err)
+		a.NotNil(cb("git.example.com:22", addr, hostKey))
+	})
+
+	t.Run("host key callback should be set on ssh-key auth", func(t *testing.T) {
+		a := assert.New(t)
+		auth, err := NewAuth(SSHKeyAuth, Options{SSHKeyFile: filepath.Join(dir, "host_key"), SSHHostKeyFingerprints: []string{gossh.FingerprintSHA256(hostKey)}})
+		a.Nil(err)
+		keys, ok := auth.Get().(*ssh.PublicKeys)
+		a.True(ok, "expected returned auth type to be of ssh public keys type")
+		if ok {
+			a.NotNil(keys.HostKeyCallback)
+			a.Nil(keys.HostKeyCallback("git.example.com:22", addr, hostKey))
+			a.NotNil(keys.HostKeyCallback("git.example.com:22", addr, otherKey))
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// netrcEntry is a machine or default entry of a netrc file
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+// newNetrcAuth returns http basic auth using the login and password of the
+// netrc entry matching the host of the remote URL. Errors never contain the
+// password
+func newNetrcAuth(opts Options) (transport.AuthMethod, error) {
+	u, err := httpURL(NetrcAuth, opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	file, err := opts.netrcFile()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read netrc file %s: %w", file, unwrapPathError(err))
+	}
+	e := matchNetrc(parseNetrc(string(b)), u.Hostname())
+	if e == nil || e.password == "" {
+		return nil, fmt.Errorf("no netrc entry with a password for host %s in %s", u.Hostname(), file)
+	}
+	username := e.login
+	if username == "" {
+		username = DefaultTokenUsername
+	}
+	return basicAuth(username, e.password), nil
+}
+
+// netrcFile returns NetrcFile if set, else the file named by the NETRC
+// environment variable or else ~/.netrc
+func (o Options) netrcFile() (string, error) {
+	if o.NetrcFile != "" {
+		return o.NetrcFile, nil
+	}
+	if f := os.Getenv("NETRC"); f != "" {
+		return f, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find netrc file: %w", err)
+	}
+	return filepath.Join(home, ".netrc"), nil
+}
+
+// parseNetrc parses the entries of a netrc file. A default entry has an empty
+// machine, macdef macros are skipped and comments start with # where a
+// keyword is expected, so values like passwords may contain #
+func parseNetrc(data string) []netrcEntry {
+	var entries []netrcEntry
+	var e *netrcEntry
+	inMacro := false
+	for _, line := range strings.Split(data, "\n") {
+		// macros run until the next empty line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			if strings.HasPrefix(fields[i], "#") {
+				break
+			}
+			switch fields[i] {
+			case "machine":
+				if i+1 < len(fields) {
+					entries = append(entries, netrcEntry{machine: fields[i+1]})
+					e = &entries[len(entries)-1]
+					i++
+				}
+			case "default":
+				entries = append(entries, netrcEntry{})
+				e = &entries[len(entries)-1]
+			case "login", "password", "account":
+				if e == nil || i+1 >= len(fields) {
+					i++
+					continue
+				}
+				switch fields[i] {
+				case "login":
+					e.login = fields[i+1]
+				case "password":
+					e.password = fields[i+1]
+				}
+				i++
+			case "macdef":
+				e = nil
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return entries
+}
+
+// matchNetrc returns the first entry for host, or the default entry if there
+// is none. It returns nil if neither exists
+func matchNetrc(entries []netrcEntry, host string) *netrcEntry {
+	var def *netrcEntry
+	for i, e := range entries {
+		if e.machine == "" {
+			if def == nil {
+				def = &entries[i]
+			}
+			continue
+		}
+		if strings.EqualFold(e.machine, host) {
+			return &entries[i]
+		}
+	}
+	return def
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := `# build credentials
+machine git.example.com login alice password s3cr3t
+machine other.example.com
+	login bob
+	password hunter2 # trailing comment
+machine hash.example.com login carol#1 password abc#123 #comment
+macdef init
+machine ignored.example.com login eve password evil
+
+default login anonymous password guest
+`
+	expected := []netrcEntry{
+		{machine: "git.example.com", login: "alice", password: "s3cr3t"},
+		{machine: "other.example.com", login: "bob", password: "hunter2"},
+		{machine: "hash.example.com", login: "carol#1", password: "abc#123"},
+		{login: "anonymous", password: "guest"},
+	}
+	entries := parseNetrc(data)
+	assert.Equal(t, expected, entries)
+
+	a := assert.New(t)
+	a.Equal(&entries[0], matchNetrc(entries, "git.example.com"))
+	a.Equal(&entries[1], matchNetrc(entries, "OTHER.example.com"))
+	a.Equal(&entries[3], matchNetrc(entries, "unknown.example.com"))
+	a.Nil(matchNetrc(entries[:3], "unknown.example.com"))
+}
+
+func TestNetrcAuth(t *testing.T) {
+	dir := t.TempDir()
+	netrc := filepath.Join(dir, "netrc")
+	err := os.WriteFile(netrc, []byte("machine git.example.com login alice password s3cr3t\nmachine token.example.com password t0ken\n"), 0600)
+	assert.Nil(t, err)
+
+	t.Run("entry should match the host of the remote url", func(t *testing.T) {
+		auth, err := NewAuth(NetrcAuth, Options{URL: "https://git.example.com:8443/avbm/groomba.git", NetrcFile: netrc})
+		assert.Nil(t, err)
+		assert.Equal(t, &http.BasicAuth{Username: "alice", Password: "s3cr3t"}, auth.Get())
+	})
+
+	t.Run("entry without login should use the default username", func(t *testing.T) {
+		auth, err := NewAuth(NetrcAuth, Options{URL: "https://token.example.com/avbm/groomba.git", NetrcFile: netrc})
+		assert.Nil(t, err)
+		assert.Equal(t, &http.BasicAuth{Username: DefaultTokenUsername, Password: "t0ken"}, auth.Get())
+	})
+
+	t.Run("NETRC should be used if netrc_file is not set", func(t *testing.T) {
+		t.Setenv("NETRC", netrc)
+		auth, err := NewAuth(NetrcAuth, Options{URL: "https://git.example.com/avbm/groomba.git"})
+		assert.Nil(t, err)
+		assert.Equal(t, &http.BasicAuth{Username: "alice", Password: "s3cr3t"}, auth.Get())
+	})
+
+	t.Run("~/.netrc should be used if neither netrc_file nor NETRC are set", func(t *testing.T) {
+		t.Setenv("NETRC", "")
+		t.Setenv("HOME", dir)
+		_, err := NewAuth(NetrcAuth, Options{URL: "https://git.example.com/avbm/groomba.git"})
+		assert.EqualError(t, err, "failed to read netrc file "+filepath.Join(dir, ".netrc")+": no such file or directory")
+	})
+
+	t.Run("unknown host should return error", func(t *testing.T) {
+		_, err := NewAuth(NetrcAuth, Options{URL: "https://unknown.example.com/avbm/groomba.git", NetrcFile: netrc})
+		assert.EqualError(t, err, "no netrc entry with a password for host unknown.example.com in "+netrc)
+	})
+
+	t.Run("ssh url should return error", func(t *testing.T) {
+		_, err := NewAuth(NetrcAuth, Options{URL: "ssh://git@git.example.com/avbm/groomba.git", NetrcFile: netrc})
+		assert.EqualError(t, err, "netrc auth requires an http or https remote, got ssh://git@git.example.com/avbm/groomba.git")
+	})
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Factory creates the auth method of an auth type from the auth options. A
+// nil auth method uses the credentials go-git finds on its own
+type Factory func(opts Options) (transport.AuthMethod, error)
+
+// Reporter is implemented by auth methods that want to know if the remote
+// accepted their credentials, eg to store or erase them
+type Reporter interface {
+	Approve() error
+	Reject() error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[AuthType]Factory{}
+)
+
+// Register makes factory available as authType for NewAuth and the auth
+// config keys. Library users can register their own auth types before
+// loading the config. It panics if authType is empty, factory is nil or
+// authType is already registered
+func Register(authType AuthType, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if authType == "" {
+		panic("auth: Register called with an empty auth type")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("auth: Register called with a nil factory for auth type %s", authType))
+	}
+	if _, ok := registry[authType]; ok {
+		panic(fmt.Sprintf("auth: Register called twice for auth type %s", authType))
+	}
+	registry[authType] = factory
+}
+
+// Registered returns the sorted names of all registered auth types
+func Registered() []AuthType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]AuthType, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// lookup returns the Factory registered for authType
+func lookup(authType AuthType) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[authType]
+	return f, ok
+}
+
+// valid returns true if the auth type t is registered
+func (t AuthType) valid() bool {
+	_, ok := lookup(t)
+	return ok
+}
+
+// unsupportedAuthTypeError returns the error for the unknown auth type
+// authType listing all registered auth types
+func unsupportedAuthTypeError(authType AuthType) error {
+	names := []string{}
+	for _, t := range Registered() {
+		names = append(names, string(t))
+	}
+	return fmt.Errorf("auth type %s not supported. valid values: %s", authType, strings.Join(names, ", "))
+}
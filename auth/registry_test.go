@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// vaultAuth is a custom auth method as a library user would register it
+type vaultAuth struct {
+	*http.BasicAuth
+	approved bool
+	rejected bool
+}
+
+func (v *vaultAuth) Approve() error {
+	v.approved = true
+	return nil
+}
+
+func (v *vaultAuth) Reject() error {
+	v.rejected = true
+	return nil
+}
+
+// registerTestAuth registers factory as authType for the duration of the test
+func registerTestAuth(t *testing.T, authType AuthType, factory Factory) {
+	Register(authType, factory)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, authType)
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("built-in auth types should be registered", func(t *testing.T) {
+		assert.Equal(t, []AuthType{AutoAuth, CredentialHelperAuth, DefaultAuth, NetrcAuth, SSHAgentAuth, SSHKeyAuth, TokenAuth}, Registered())
+	})
+
+	t.Run("registered auth types should be usable by NewAuth", func(t *testing.T) {
+		a := assert.New(t)
+		va := &vaultAuth{}
+		registerTestAuth(t, "vault", func(opts Options) (transport.AuthMethod, error) {
+			va.BasicAuth = &http.BasicAuth{Username: opts.TokenUsername, Password: "from-vault"}
+			return va, nil
+		})
+
+		auth, err := NewAuth("vault", Options{TokenUsername: "alice"})
+		a.Nil(err)
+		a.Equal(va, auth.Get())
+		a.Equal("alice", va.Username)
+
+		a.Nil(auth.Approve())
+		a.Nil(auth.Reject())
+		a.True(va.approved)
+		a.True(va.rejected)
+
+		_, err = NewAuth("unknown", Options{})
+		a.EqualError(err, "auth type unknown not supported. valid values: auto, credential-helper, default, netrc, ssh-agent, ssh-key, token, vault")
+
+		_, err = NewResolver(Definition{Type: "vault"}, nil)
+		a.Nil(err)
+	})
+
+	t.Run("factory errors should be returned by NewAuth", func(t *testing.T) {
+		registerTestAuth(t, "broken", func(Options) (transport.AuthMethod, error) {
+			return nil, fmt.Errorf("vault is sealed")
+		})
+		_, err := NewAuth("broken", Options{})
+		assert.EqualError(t, err, "vault is sealed")
+	})
+
+	t.Run("invalid registrations should panic", func(t *testing.T) {
+		a := assert.New(t)
+		factory := func(Options) (transport.AuthMethod, error) { return nil, nil }
+		a.PanicsWithValue("auth: Register called twice for auth type token", func() { Register(TokenAuth, factory) })
+		a.PanicsWithValue("auth: Register called with an empty auth type", func() { Register("", factory) })
+		a.PanicsWithValue("auth: Register called with a nil factory for auth type vault", func() { Register("vault", nil) })
+	})
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Definition is an auth type together with the options it uses
+type Definition struct {
+	Type    AuthType `mapstructure:"auth" yaml:"auth" toml:"auth"`
+	Options `mapstructure:",squash" yaml:",inline" toml:",inline"`
+}
+
+// HostAuth is the Definition used for remotes whose url matches Host and
+// URLPrefix. Host is a glob pattern matched against the host name of the
+// remote url, eg *.example.com, URLPrefix is matched against the start of the
+// whole url. Empty fields match any remote
+type HostAuth struct {
+	Host       string `mapstructure:"host" yaml:"host" toml:"host"`
+	URLPrefix  string `mapstructure:"url_prefix" yaml:"url_prefix" toml:"url_prefix"`
+	Definition `mapstructure:",squash" yaml:",inline" toml:",inline"`
+}
+
+// hostAuthKeys are the config keys of an auth_hosts entry, the mapstructure
+// tags of HostAuth and the Definition and Options it squashes
+var hostAuthKeys = []string{
+	"auth",
+	"host",
+	"netrc_file",
+	"ssh_host_key_fingerprints",
+	"ssh_insecure_ignore_host_key",
+	"ssh_key_file",
+	"ssh_key_passphrase_env",
+	"ssh_key_passphrase_file",
+	"ssh_known_hosts_file",
+	"ssh_user",
+	"token_env",
+	"token_file",
+	"token_username",
+	"url_prefix",
+}
+
+// HostAuthKeys returns the sorted config keys an auth_hosts entry can set
+func HostAuthKeys() []string {
+	return append([]string{}, hostAuthKeys...)
+}
+
+// DefinitionProblems returns every problem of def and hosts that NewResolver
+// or creating their auth methods would fail with: unsupported auth types,
+// auth_hosts entries matching every remote or with invalid host patterns and
+// missing options. No credentials are read
+func DefinitionProblems(def Definition, hosts []HostAuth) []string {
+	problems := def.problems()
+	for i, h := range hosts {
+		entry := fmt.Sprintf("auth_hosts entry %d", i+1)
+		if h.Host == "" && h.URLPrefix == "" {
+			problems = append(problems, fmt.Sprintf("%s with auth %s must set host or url_prefix", entry, h.Type))
+		}
+		if _, err := path.Match(h.Host, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("%s has invalid host pattern %s: %s", entry, h.Host, err))
+		}
+		for _, p := range h.Definition.problems() {
+			problems = append(problems, fmt.Sprintf("%s: %s", entry, p))
+		}
+	}
+	return problems
+}
+
+// problems returns the problems of the auth type of d and the options it
+// can't work without
+func (d Definition) problems() []string {
+	if !d.Type.valid() {
+		return []string{unsupportedAuthTypeError(d.Type).Error()}
+	}
+	switch {
+	case d.Type == TokenAuth && d.TokenEnv == "" && d.TokenFile == "":
+		return []string{fmt.Sprintf("auth %s needs token_env or token_file to be set", d.Type)}
+	case d.Type == SSHKeyAuth && d.SSHKeyFile == "":
+		return []string{fmt.Sprintf("auth %s needs ssh_key_file to be set", d.Type)}
+	}
+	return nil
+}
+
+// matches returns true if the remote rawURL matches both Host and URLPrefix
+func (h HostAuth) matches(rawURL string) bool {
+	if h.URLPrefix != "" && !strings.HasPrefix(rawURL, h.URLPrefix) {
+		return false
+	}
+	if h.Host != "" {
+		ok, _ := path.Match(strings.ToLower(h.Host), strings.ToLower(urlHost(rawURL)))
+		return ok
+	}
+	return true
+}
+
+// Resolver picks the auth method of a remote by its url. The first of hosts
+// matching the url is used, else the default Definition. Auth methods are
+// created on first use and cached per url
+type Resolver struct {
+	def   Definition
+	hosts []HostAuth
+
+	mu    sync.Mutex
+	auths map[string]*Auth
+}
+
+// NewResolver returns a Resolver using the Definition of the first matching
+// entry of hosts or def for remotes not matching any of them
+func NewResolver(def Definition, hosts []HostAuth) (*Resolver, error) {
+	if !def.Type.valid() {
+		return nil, unsupportedAuthTypeError(def.Type)
+	}
+	for _, h := range hosts {
+		if h.Host == "" && h.URLPrefix == "" {
+			return nil, fmt.Errorf("auth_hosts entry with auth %s must set host or url_prefix", h.Type)
+		}
+		if _, err := path.Match(h.Host, ""); err != nil {
+			return nil, fmt.Errorf("auth_hosts entry has invalid host pattern %s: %s", h.Host, err)
+		}
+		if !h.Type.valid() {
+			return nil, unsupportedAuthTypeError(h.Type)
+		}
+	}
+	return &Resolver{def: def, hosts: hosts, auths: map[string]*Auth{}}, nil
+}
+
+// definition returns the Definition used for the remote rawURL
+func (r *Resolver) definition(rawURL string) Definition {
+	for _, h := range r.hosts {
+		if h.matches(rawURL) {
+			return h.Definition
+		}
+	}
+	return r.def
+}
+
+// auth returns the cached Auth of the remote rawURL, creating it on first use
+func (r *Resolver) auth(rawURL string) (*Auth, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.auths[rawURL]; ok {
+		return a, nil
+	}
+	def := r.definition(rawURL)
+	opts := def.Options
+	opts.URL = rawURL
+	a, err := NewAuth(def.Type, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize %s auth for %s: %w", def.Type, redactURL(rawURL), err)
+	}
+	r.auths[rawURL] = a
+	return a, nil
+}
+
+// Get returns the auth method for the remote rawURL
+func (r *Resolver) Get(rawURL string) (transport.AuthMethod, error) {
+	a, err := r.auth(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return a.Get(), nil
+}
+
+// Approve reports that the remote rawURL accepted its credentials
+func (r *Resolver) Approve(rawURL string) error {
+	a, err := r.auth(rawURL)
+	if err != nil {
+		return err
+	}
+	return a.Approve()
+}
+
+// Reject reports that the remote rawURL rejected its credentials
+func (r *Resolver) Reject(rawURL string) error {
+	a, err := r.auth(rawURL)
+	if err != nil {
+		return err
+	}
+	return a.Reject()
+}
+
+// urlHost returns the host name of the remote rawURL which can also use the
+// scp-like syntax user@host:path of ssh remotes
+func urlHost(rawURL string) string {
+	if strings.Contains(rawURL, "://") {
+		if u, err := url.Parse(rawURL); err == nil {
+			return u.Hostname()
+		}
+		return ""
+	}
+	host, _, ok := strings.Cut(rawURL, ":")
+	if !ok {
+		// local paths have no host
+		return ""
+	}
+	if _, h, ok := strings.Cut(host, "@"); ok {
+		host = h
+	}
+	return host
+}
@@ -0,0 +1,102 @@
+package main
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/avbm/groomba"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the groomba config",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config and print every problem found",
+	Long: `Check the config from the config file, environment variables and flags and
+print every problem found. With --url the config file is read from the default
+branch of the repository at the url.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, err := loadConfig(cmd)
+
+		var v *groomba.ConfigValidationError
+		if errors.As(err, &v) {
+			for _, p := range v.Problems() {
+				log.Error(p)
+			}
+			log.Fatalf("config has %d problem(s)", len(v.Problems()))
+		}
+		groomba.CheckIfError(err, "failed to load config")
+		log.Info("config is valid")
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective config",
+	Long: `Print the effective config as YAML after merging the defaults, config files,
+environment variables and flags. With --origin every value is annotated with
+where it was set.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig(cmd)
+		groomba.CheckIfError(err, "failed to load config")
+
+		origin, err := cmd.Flags().GetBool("origin")
+		groomba.CheckIfError(err, "failed to read flag origin")
+		err = cfg.Print(origin)
+		groomba.CheckIfError(err, "failed to print config")
+	},
+}
+
+func init() {
+	configShowCmd.Flags().Bool("origin", false, "annotate every value with where it was set")
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadConfig loads the config of the repository in the current directory, or
+// with --url or remote_config from the default branch of the remote
+func loadConfig(cmd *cobra.Command) (*groomba.Config, error) {
+	groomba.BindFlags(cmd.Flags())
+
+	url, err := cmd.Flags().GetString("url")
+	groomba.CheckIfError(err, "failed to read flag url")
+	var g groomba.Groomba
+	if url != "" {
+		g, err = groomba.OpenURL(url)
+	} else {
+		var cfg *groomba.Config
+		cfg, err = groomba.GetConfig(".")
+		if err != nil || !cfg.RemoteConfig {
+			return cfg, err
+		}
+		g, err = groomba.OpenPath(".")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g.Config(), nil
+}
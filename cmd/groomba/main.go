@@ -19,45 +19,117 @@ package main
 import (
 	"time"
 
-	git "github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
 	"github.com/avbm/groomba"
-	"github.com/avbm/groomba/auth"
 )
 
-func main() {
-	log.SetHandler(cli.Default)
-	cfg, err := groomba.GetConfig(".")
-	groomba.CheckIfError(err, "failed to get configs")
-
-	repo, err := git.PlainOpen(".")
-	groomba.CheckIfError(err, "failed to open repository")
-
-	a, err := auth.NewAuth(cfg.Auth)
-	groomba.CheckIfError(err, "failed to initialize auth")
-
-	err = repo.Fetch(&git.FetchOptions{
-		RemoteName: "origin",
-		//RefSpecs:   []config.RefSpec{"refs/remotes/origin"},
-		RefSpecs: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
-		Depth:    1,
-		Auth:     a.Get(),
-	})
-	if err != git.NoErrAlreadyUpToDate {
-		groomba.CheckIfError(err, "failed to fetch references from upstream")
+var rootCmd = &cobra.Command{
+	Use:   "groomba",
+	Short: "Groomba grooms your git repositories by renaming stale branches",
+	Long: `Groomba grooms your git repositories by renaming branches older than a defined age.
+
+Run without a subcommand it behaves like "groomba move". Every config option can
+be set in a .groomba.yaml or .groomba.toml file, as a GROOMBA_* environment
+variable or as a command line flag. Flags take the highest precedence.`,
+	Args: cobra.NoArgs,
+	Run:  runMove,
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.String("auth", "", `type of authentication to use, valid values: "default", "auto", "credential-helper", "netrc", "ssh-agent", "ssh-key", "token" (default "default")`)
+	flags.Bool("auto-revive", false, "move stale branches with new commits back to their original names")
+	flags.String("ca-bundle-file", "", "path of a PEM file with CA certificates trusted in addition to the system ones")
+	flags.Bool("clobber", false, "overwrite existing stale branches that are not fast-forward merge-able")
+	flags.String("config", "", "path of a config file taking precedence over the discovered config files (default $GROOMBA_CONFIG)")
+	flags.Int("delete-age-threshold", 0, "age in days after which a branch is deleted instead of moved, 0 disables deleting")
+	flags.Bool("dry-run", false, "only print the branches that would be moved without moving them")
+	flags.StringSlice("exclude-patterns", nil, `glob or /regex/ patterns of branches to ignore (default [/revert.*/,/cherry-pick.*/])`)
+	flags.StringSlice("include-patterns", nil, "glob or /regex/ patterns of branches to groom, all branches are groomed if empty")
+	flags.Bool("insecure-skip-tls-verify", false, "disable TLS certificate verification of https remotes, this is insecure")
+	flags.Uint8("max-concurrency", 0, "maximum number of concurrent workers (default 4)")
+	flags.String("netrc-file", "", `path of the netrc file for netrc auth (default $NETRC or "~/.netrc")`)
+	flags.StringSlice("no-proxy", nil, "hosts that are connected to without --proxy-url (default $NO_PROXY)")
+	flags.String("prefix", "", `prefix added to the names of stale branches (default "stale/")`)
+	flags.Bool("protect-default-branch", true, "treat the default branch of each remote as a static branch")
+	flags.String("proxy-url", "", "url of the proxy used for http and https remotes")
+	flags.Int("purge-age-threshold", 0, "age in days after which an already stale branch is deleted, 0 disables purging")
+	flags.String("remote", "", `name of the git remote to groom (default "origin")`)
+	flags.Bool("remote-config", false, "read the config file from the default branch of the remote instead of the checkout")
+	flags.StringSlice("remotes", nil, "names of all git remotes to groom, overrides --remote")
+	flags.Int("stale-age-threshold", 0, "age in days after which a branch is considered stale (default 14)")
+	flags.StringSlice("ssh-host-key-fingerprints", nil, "pinned SHA256 fingerprints of accepted ssh host keys")
+	flags.Bool("ssh-insecure-ignore-host-key", false, "disable ssh host key verification, this is insecure")
+	flags.String("ssh-key-file", "", "path of the private key for ssh-key auth")
+	flags.String("ssh-key-passphrase-env", "", "name of the environment variable holding the passphrase of the ssh key")
+	flags.String("ssh-key-passphrase-file", "", "path of the file holding the passphrase of the ssh key")
+	flags.String("ssh-known-hosts-file", "", "path of the known_hosts file used to verify ssh host keys")
+	flags.String("ssh-user", "", `user for the ssh-agent and ssh-key auth types (default "git")`)
+	flags.StringSlice("static-branches", nil, "branches that are protected and will be ignored (default [main,master,production])")
+	flags.String("token-env", "", "name of the environment variable holding the token for token auth")
+	flags.String("token-file", "", "path of the file holding the token for token auth")
+	flags.String("token-username", "", `username sent with the token for token auth (default "git")`)
+	flags.String("url", "", "url of a repository to groom in memory without a local checkout, its config is read from the default branch")
+}
+
+// setup loads the config, opens the repository in the current directory and
+// fetches the latest references from the configured remote. With --url it
+// uses an in-memory repository of the url instead
+func setup(cmd *cobra.Command) groomba.Groomba {
+	groomba.BindFlags(cmd.Flags())
+
+	url, err := cmd.Flags().GetString("url")
+	groomba.CheckIfError(err, "failed to read flag url")
+
+	var g groomba.Groomba
+	if url != "" {
+		g, err = groomba.OpenURL(url)
+	} else {
+		g, err = groomba.OpenPath(".")
 	}
+	groomba.CheckIfError(err, "failed to setup")
+	return g
+}
 
-	g := groomba.NewGroomba(cfg, repo, a)
+// printFilteredBranches prints the branches to move, delete, purge and revive
+// grouped by author
+func printFilteredBranches(g groomba.Groomba) {
+	now := time.Now()
 
-	fb, err := g.FilterBranches(time.Now())
+	move, err := g.FilterBranches(now)
 	groomba.CheckIfError(err, "failed to filter stale branches")
+	err = g.PrintBranchesGroupbyAuthor(move)
+	groomba.CheckIfError(err, "failed to print branches by author")
+
+	del, err := g.FilterDeleteBranches(now)
+	groomba.CheckIfError(err, "failed to filter branches to delete")
+	printBranches(g, "Branches to delete:", del)
+
+	purge, err := g.FilterPurgeBranches(now)
+	groomba.CheckIfError(err, "failed to filter stale branches to purge")
+	printBranches(g, "Stale branches to purge:", purge)
+
+	revive, err := g.FilterReviveBranches(now)
+	groomba.CheckIfError(err, "failed to filter stale branches to revive")
+	printBranches(g, "Stale branches to revive:", revive)
+}
 
-	err = g.PrintBranchesGroupbyAuthor(fb)
+// printBranches prints branches grouped by author under a title if there are any
+func printBranches(g groomba.Groomba, title string, branches []*plumbing.Reference) {
+	if len(branches) == 0 {
+		return
+	}
+	log.Info(title)
+	err := g.PrintBranchesGroupbyAuthor(branches)
 	groomba.CheckIfError(err, "failed to print branches by author")
+}
 
-	err = g.MoveStaleBranches(fb)
-	groomba.CheckIfError(err, "failed to move stale branches")
+func main() {
+	log.SetHandler(cli.Default)
+	err := rootCmd.Execute()
+	groomba.CheckIfError(err, "failed to execute command")
 }
@@ -0,0 +1,44 @@
+package main
+
+/*
+   Copyright 2020 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/avbm/groomba"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move",
+	Short: "Rename stale branches by adding the configured prefix, delete, purge and revive branches",
+	Args:  cobra.NoArgs,
+	Run:   runMove,
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+}
+
+func runMove(cmd *cobra.Command, args []string) {
+	g := setup(cmd)
+	printFilteredBranches(g)
+
+	_, err := g.Groom(time.Now())
+	groomba.CheckIfError(err, "failed to groom branches")
+}
@@ -0,0 +1,45 @@
+package main
+
+/*
+   Copyright 2020 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/avbm/groomba"
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete stale branches older than the purge age threshold",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		g := setup(cmd)
+
+		pb, err := g.FilterPurgeBranches(time.Now())
+		groomba.CheckIfError(err, "failed to filter stale branches to purge")
+		printBranches(g, "Stale branches to purge:", pb)
+
+		err = g.DeleteStaleBranches(pb)
+		groomba.CheckIfError(err, "failed to purge stale branches")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+}
@@ -0,0 +1,43 @@
+package main
+
+/*
+   Copyright 2020 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/avbm/groomba"
+)
+
+var reviveCmd = &cobra.Command{
+	Use:   "revive <branch>...",
+	Short: "Restore stale branches to their original names",
+	Long: `Restore stale branches to their original names.
+
+Each branch is given by its original name without the prefix, for example
+"groomba revive foo" copies stale/foo back to foo and deletes stale/foo.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		g := setup(cmd)
+
+		err := g.ReviveBranches(args)
+		groomba.CheckIfError(err, "failed to revive branches")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reviveCmd)
+}
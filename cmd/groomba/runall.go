@@ -0,0 +1,58 @@
+package main
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/avbm/groomba"
+)
+
+var runAllCmd = &cobra.Command{
+	Use:   "run-all <manifest>",
+	Short: "Groom every repository listed in a manifest file",
+	Long: `Groom every repository listed in a manifest file like "groomba move" does.
+
+The manifest lists repositories by url, groomed in memory, or by path of a local
+checkout, each with optional config overrides. Up to max_concurrency
+repositories are groomed at the same time and one report for all of them is
+printed. Command line flags apply to every repository.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url, err := cmd.Flags().GetString("url")
+		groomba.CheckIfError(err, "failed to read flag url")
+		if url != "" {
+			groomba.CheckIfError(fmt.Errorf("--url can't be used with run-all"), "failed to setup")
+		}
+		groomba.BindFlags(cmd.Flags())
+
+		m, err := groomba.LoadManifest(args[0])
+		groomba.CheckIfError(err, "failed to load manifest")
+
+		report, runErr := m.RunAll(time.Now())
+		err = report.Print()
+		groomba.CheckIfError(err, "failed to print report")
+		groomba.CheckIfError(runErr, "failed to groom repositories")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runAllCmd)
+}
@@ -0,0 +1,43 @@
+package main
+
+/*
+   Copyright 2020 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// VERSION, COMMIT and DATE are set at build time using -ldflags
+var (
+	VERSION = "dev"
+	COMMIT  = "none"
+	DATE    = "unknown"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version of groomba",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("groomba %s (commit: %s, built: %s)\n", VERSION, COMMIT, DATE)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
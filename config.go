@@ -18,72 +18,370 @@ package groomba
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/avbm/groomba/auth"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Config stores the configuration for Groomba
 type Config struct {
-	Auth              auth.AuthType `yaml:"auth" toml:"auth"`
-	Clobber           bool          `yaml:"clobber" toml:"clobber"`
-	DryRun            bool          `yaml:"dry_run" toml:"dry_run"`
-	MaxConcurrency    uint8         `yaml:"max_concurrency" toml:"max_concurrency"`
-	Prefix            string        `yaml:"prefix" toml:"prefix"`
-	StaleAgeThreshold int           `yaml:"stale_age_threshold" toml:"stale_age_threshold"`
-	StaticBranches    []string      `yaml:"static_branches" toml:"static_branches"`
+	Auth                  auth.AuthType   `yaml:"auth" toml:"auth"`
+	AuthHosts             []auth.HostAuth `yaml:"auth_hosts" toml:"auth_hosts"`
+	AutoRevive            bool            `yaml:"auto_revive" toml:"auto_revive"`
+	CABundleFile          string          `yaml:"ca_bundle_file" toml:"ca_bundle_file"`
+	Clobber               bool            `yaml:"clobber" toml:"clobber"`
+	DeleteAgeThreshold    int             `yaml:"delete_age_threshold" toml:"delete_age_threshold"`
+	DryRun                bool            `yaml:"dry_run" toml:"dry_run"`
+	ExcludePatterns       []string        `yaml:"exclude_patterns" toml:"exclude_patterns"`
+	IncludePatterns       []string        `yaml:"include_patterns" toml:"include_patterns"`
+	InsecureSkipTLSVerify bool            `yaml:"insecure_skip_tls_verify" toml:"insecure_skip_tls_verify"`
+	MaxConcurrency        uint8           `yaml:"max_concurrency" toml:"max_concurrency"`
+	NoProxy               []string        `yaml:"no_proxy" toml:"no_proxy"`
+	Prefix                string          `yaml:"prefix" toml:"prefix"`
+	ProtectDefaultBranch  bool            `yaml:"protect_default_branch" toml:"protect_default_branch"`
+	ProxyURL              string          `yaml:"proxy_url" toml:"proxy_url"`
+	PurgeAgeThreshold     int             `yaml:"purge_age_threshold" toml:"purge_age_threshold"`
+	Remote                string          `yaml:"remote" toml:"remote"`
+	RemoteConfig          bool            `yaml:"remote_config" toml:"remote_config"`
+	Remotes               []string        `yaml:"remotes" toml:"remotes"`
+	StaleAgeThreshold     int             `yaml:"stale_age_threshold" toml:"stale_age_threshold"`
+	StaticBranches        []string        `yaml:"static_branches" toml:"static_branches"`
+
+	// Options stores the settings of the auth types
+	auth.Options `mapstructure:",squash" yaml:",inline" toml:",inline"`
+
+	// caBundle is the content of CABundleFile
+	caBundle []byte
+	// keyProblems are the keys of the config files that are not config keys
+	// or not allowed in their config file
+	keyProblems []string
+	// origins maps config keys to where their value was set, see Origin
+	origins map[string]string
+	// patterns are the compiled ExcludePatterns, IncludePatterns and
+	// StaticBranches by pattern, set by Validate
+	patterns map[string]pattern
+}
+
+// configKeys lists every config key, each can be set in a config file, as an
+// environment variable GROOMBA_<KEY> or as a command line flag --<key> with
+// underscores replaced by dashes
+var configKeys = []string{
+	"auth",
+	"auto_revive",
+	"ca_bundle_file",
+	"clobber",
+	"delete_age_threshold",
+	"dry_run",
+	"exclude_patterns",
+	"include_patterns",
+	"insecure_skip_tls_verify",
+	"max_concurrency",
+	"netrc_file",
+	"no_proxy",
+	"prefix",
+	"protect_default_branch",
+	"proxy_url",
+	"purge_age_threshold",
+	"remote",
+	"remote_config",
+	"remotes",
+	"stale_age_threshold",
+	"ssh_host_key_fingerprints",
+	"ssh_insecure_ignore_host_key",
+	"ssh_key_file",
+	"ssh_key_passphrase_env",
+	"ssh_key_passphrase_file",
+	"ssh_known_hosts_file",
+	"ssh_user",
+	"static_branches",
+	"token_env",
+	"token_file",
+	"token_username",
+}
+
+// policyKeys lists the config keys that decide how branches are groomed, the
+// only ones the config file of the default branch may set. The other keys
+// control credentials, TLS and proxies or which remote is groomed
+var policyKeys = []string{
+	"auto_revive",
+	"clobber",
+	"delete_age_threshold",
+	"dry_run",
+	"exclude_patterns",
+	"include_patterns",
+	"prefix",
+	"protect_default_branch",
+	"purge_age_threshold",
+	"stale_age_threshold",
+	"static_branches",
+}
+
+// oneWayPolicyKeys are the policyKeys the config file of the default branch
+// may only set to their safe value, so it can't turn off a dry_run or turn on
+// clobber the operator set in the system or user config
+var oneWayPolicyKeys = map[string]bool{
+	"clobber": false,
+	"dry_run": true,
+}
+
+// ConfigLoader loads Configs from defaults, config files, environment
+// variables and bound flags. Every load uses its own viper instance, so
+// Configs loaded one after another or concurrently never share settings
+type ConfigLoader struct {
+	flags *pflag.FlagSet
+	// configFile is the path of the explicit config file set with SetConfigFile
+	configFile string
+}
+
+// NewConfigLoader returns a ConfigLoader without bound flags
+func NewConfigLoader() *ConfigLoader {
+	return &ConfigLoader{}
+}
+
+// defaultLoader is used by GetConfig, GetConfigFromTree, OpenPath, OpenURL
+// and RunAll
+var defaultLoader = NewConfigLoader()
+
+// BindFlags binds command line flags to their config keys for the Configs
+// loaded by GetConfig and friends, see ConfigLoader.BindFlags
+func BindFlags(flags *pflag.FlagSet) {
+	defaultLoader.BindFlags(flags)
 }
 
+// BindFlags binds command line flags to their config keys so that flags set
+// on the command line take precedence over environment variables and config
+// files. Flags are looked up by config key with underscores replaced by dashes
+func (l *ConfigLoader) BindFlags(flags *pflag.FlagSet) {
+	l.flags = flags
+}
+
+// GetConfig loads the config using the .groomba config file in configPath
 func GetConfig(configPath string) (*Config, error) {
-	viper.SetConfigName(".groomba")
-	viper.AddConfigPath(configPath) // should be "." except for tests
+	return defaultLoader.Load(configPath)
+}
 
-	viper.SetDefault("auth", auth.DefaultAuth)
-	viper.RegisterAlias("DryRun", "dry_run")
-	viper.SetDefault("stale_age_threshold", 14)
-	viper.RegisterAlias("StaleAgeThreshold", "stale_age_threshold")
-	viper.SetDefault("static_branches", []string{"main", "master", "production"})
-	viper.RegisterAlias("StaticBranches", "static_branches")
-	viper.SetDefault("prefix", "stale/")
-	viper.SetDefault("max_concurrency", 4)
-	viper.RegisterAlias("MaxConcurrency", "max_concurrency")
+// GetConfigFromTree loads the config like GetConfig but reads the .groomba
+// config file from the root of tree, eg of the default branch of a remote
+// when there is no local checkout. A nil tree or one without a config file
+// only uses the defaults, environment variables and flags
+func GetConfigFromTree(tree *object.Tree) (*Config, error) {
+	return defaultLoader.LoadFromTree(tree)
+}
 
-	if err := viper.BindEnv("clobber", "GROOMBA_CLOBBER"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env clobber: %s", err)
-	}
-	if err := viper.BindEnv("dry_run", "GROOMBA_DRY_RUN"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env dry_run: %s", err)
+// Load loads the config using the config files of the repository checked out
+// at dir, see dirLayers
+func (l *ConfigLoader) Load(dir string) (*Config, error) {
+	return l.fromDir(dir, nil)
+}
+
+// LoadFromTree loads the config using the .groomba config file in the root
+// of tree instead of the ones of a checkout, see GetConfigFromTree
+func (l *ConfigLoader) LoadFromTree(tree *object.Tree) (*Config, error) {
+	return l.fromTree(tree, nil)
+}
+
+// fromTree loads the config with the .groomba config file in tree and
+// overrides applied on top of the config files
+func (l *ConfigLoader) fromTree(tree *object.Tree, overrides map[string]interface{}) (*Config, error) {
+	layers, err := l.treeLayers(tree)
+	if err != nil {
+		return nil, fmt.Errorf("getConfig: failed to read in config: %s", err)
 	}
-	if err := viper.BindEnv("max_concurrency", "GROOMBA_MAX_CONCURRENCY"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env max_concurrency: %s", err)
+	return l.load(layers, overrides)
+}
+
+// fromDir loads the config with the config files of the checkout at dir and
+// overrides applied on top of the config files
+func (l *ConfigLoader) fromDir(dir string, overrides map[string]interface{}) (*Config, error) {
+	layers, err := l.dirLayers(dir)
+	if err != nil {
+		return nil, fmt.Errorf("getConfig: failed to read in config: %s", err)
 	}
-	if err := viper.BindEnv("prefix", "GROOMBA_PREFIX"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env prefix: %s", err)
+	return l.load(layers, overrides)
+}
+
+// isConfigKey returns true if key is a config key that can be set in a
+// config file
+func isConfigKey(key string) bool {
+	if key == "auth_hosts" {
+		return true
 	}
-	if err := viper.BindEnv("stale_age_threshold", "GROOMBA_STALE_AGE_THRESHOLD"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env stale_age_threshold: %s", err)
+	for _, k := range configKeys {
+		if k == key {
+			return true
+		}
 	}
-	if err := viper.BindEnv("static_branches", "GROOMBA_STATIC_BRANCHES"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env static_branches: %s", err)
+	return false
+}
+
+// isPolicyKey returns true if key is one of the policyKeys
+func isPolicyKey(key string) bool {
+	for _, k := range policyKeys {
+		if k == key {
+			return true
+		}
 	}
-	if err := viper.BindEnv("auth", "GROOMBA_AUTH"); err != nil {
-		return nil, fmt.Errorf("getConfig: failed to bind env auth: %s", err)
+	return false
+}
+
+// isSafePolicyValue returns true if value is the safe value of key or key is
+// not one of the oneWayPolicyKeys
+func isSafePolicyValue(key string, value interface{}) bool {
+	safe, ok := oneWayPolicyKeys[key]
+	return !ok || fmt.Sprint(value) == strconv.FormatBool(safe)
+}
+
+// newViper returns a viper instance with the defaults, aliases, environment
+// variables and the flags of l bound
+func (l *ConfigLoader) newViper() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetDefault("auth", auth.DefaultAuth)
+	v.RegisterAlias("AuthHosts", "auth_hosts")
+	v.SetDefault("auto_revive", false)
+	v.RegisterAlias("AutoRevive", "auto_revive")
+	v.RegisterAlias("CABundleFile", "ca_bundle_file")
+	v.SetDefault("delete_age_threshold", 0)
+	v.RegisterAlias("DeleteAgeThreshold", "delete_age_threshold")
+	v.RegisterAlias("DryRun", "dry_run")
+	v.SetDefault("exclude_patterns", []string{"/revert.*/", "/cherry-pick.*/"})
+	v.RegisterAlias("ExcludePatterns", "exclude_patterns")
+	v.SetDefault("include_patterns", []string{})
+	v.RegisterAlias("IncludePatterns", "include_patterns")
+	v.RegisterAlias("InsecureSkipTLSVerify", "insecure_skip_tls_verify")
+	v.RegisterAlias("NoProxy", "no_proxy")
+	v.RegisterAlias("ProxyURL", "proxy_url")
+	v.SetDefault("stale_age_threshold", 14)
+	v.RegisterAlias("StaleAgeThreshold", "stale_age_threshold")
+	v.SetDefault("static_branches", []string{"main", "master", "production"})
+	v.RegisterAlias("StaticBranches", "static_branches")
+	v.SetDefault("prefix", "stale/")
+	v.SetDefault("protect_default_branch", true)
+	v.RegisterAlias("ProtectDefaultBranch", "protect_default_branch")
+	v.SetDefault("purge_age_threshold", 0)
+	v.RegisterAlias("PurgeAgeThreshold", "purge_age_threshold")
+	v.SetDefault("remote", "origin")
+	v.RegisterAlias("RemoteConfig", "remote_config")
+	v.SetDefault("max_concurrency", 4)
+	v.RegisterAlias("MaxConcurrency", "max_concurrency")
+
+	for _, key := range configKeys {
+		if err := v.BindEnv(key, "GROOMBA_"+strings.ToUpper(key)); err != nil {
+			return nil, fmt.Errorf("getConfig: failed to bind env %s: %s", key, err)
+		}
+		if l.flags == nil {
+			continue
+		}
+		f := l.flags.Lookup(strings.ReplaceAll(key, "_", "-"))
+		if f == nil {
+			continue
+		}
+		if err := v.BindPFlag(key, f); err != nil {
+			return nil, fmt.Errorf("getConfig: failed to bind flag %s: %s", f.Name, err)
+		}
 	}
+	return v, nil
+}
 
-	err := viper.ReadInConfig()
+// load merges the config layers in order, applies overrides on top of them
+// and returns the validated Config
+func (l *ConfigLoader) load(layers []configLayer, overrides map[string]interface{}) (*Config, error) {
+	for key := range overrides {
+		if !isConfigKey(key) {
+			return nil, fmt.Errorf("getConfig: unknown config key %s", key)
+		}
+	}
+	v, err := l.newViper()
 	if err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("getConfig: failed to read in config: %s", err)
+		return nil, err
+	}
+
+	origins := map[string]string{}
+	var keyProblems []string
+	for _, layer := range layers {
+		settings, err := configFileSettings(layer.configType, layer.contents)
+		if err != nil {
+			return nil, fmt.Errorf("getConfig: failed to read in config %s: %s", layer.origin, err)
+		}
+		for _, key := range sortedKeys(settings) {
+			switch {
+			case !isConfigKey(key):
+				keyProblems = append(keyProblems, fmt.Sprintf("unknown config key %s in %s", unknownKeyHint(key, append([]string{"auth_hosts"}, configKeys...)), layer.origin))
+			case layer.policyOnly && !isPolicyKey(key):
+				keyProblems = append(keyProblems, fmt.Sprintf("config key %s in %s can't be set from the default branch", key, layer.origin))
+				delete(settings, key)
+			case layer.policyOnly && !isSafePolicyValue(key, settings[key]):
+				keyProblems = append(keyProblems, fmt.Sprintf("config key %s in %s can only be set to %t from the default branch", key, layer.origin, oneWayPolicyKeys[key]))
+				delete(settings, key)
+			default:
+				origins[key] = layer.origin
+			}
+		}
+		keyProblems = append(keyProblems, hostAuthKeyProblems(settings["auth_hosts"], layer.origin)...)
+		if layer.policyOnly {
+			if err := v.MergeConfigMap(settings); err != nil {
+				return nil, fmt.Errorf("getConfig: failed to read in config %s: %s", layer.origin, err)
+			}
+			continue
+		}
+		v.SetConfigType(layer.configType)
+		if err := v.MergeConfig(strings.NewReader(layer.contents)); err != nil {
+			return nil, fmt.Errorf("getConfig: failed to read in config %s: %s", layer.origin, err)
+		}
+	}
+	if err := v.MergeConfigMap(overrides); err != nil {
+		return nil, fmt.Errorf("getConfig: failed to read in config: %s", err)
+	}
+	for key := range overrides {
+		origins[key] = "manifest"
+	}
+	keyProblems = append(keyProblems, hostAuthKeyProblems(overrides["auth_hosts"], "manifest")...)
+	for _, key := range configKeys {
+		if env := "GROOMBA_" + strings.ToUpper(key); os.Getenv(env) != "" {
+			origins[key] = "environment variable " + env
+		}
+		if l.flags == nil {
+			continue
+		}
+		if f := l.flags.Lookup(strings.ReplaceAll(key, "_", "-")); f != nil && f.Changed {
+			origins[key] = "flag --" + f.Name
 		}
 	}
 
-	log.Debugf("%v", viper.AllSettings())
+	log.Debugf("%v", v.AllSettings())
 	var cfg Config
-	err = viper.Unmarshal(&cfg)
+	err = v.Unmarshal(&cfg)
 	if err != nil {
 		return nil, fmt.Errorf("getConfig: failed to unmarshal config: %s", err)
 	}
+	cfg.keyProblems = keyProblems
+	cfg.origins = origins
+
+	// if remotes are set then the first one is the primary remote
+	if len(cfg.Remotes) > 0 {
+		cfg.Remote = cfg.Remotes[0]
+		cfg.origins["remote"] = cfg.Origin("remotes")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("getConfig: %w", err)
+	}
+
+	if cfg.CABundleFile != "" {
+		cfg.caBundle, err = os.ReadFile(cfg.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("getConfig: failed to read ca_bundle_file: %s", err)
+		}
+	}
 
 	// if max_concurrency is set to 0 then override to 1
 	if cfg.MaxConcurrency == 0 {
@@ -92,3 +390,184 @@ func GetConfig(configPath string) (*Config, error) {
 
 	return &cfg, nil
 }
+
+// configFileSettings returns the settings of the config file contents of
+// configType by their top level key
+func configFileSettings(configType, contents string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigType(configType)
+	if err := v.ReadConfig(strings.NewReader(contents)); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// sortedKeys returns the keys of settings in sorted order
+func sortedKeys(settings map[string]interface{}) []string {
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hostAuthKeyProblems returns the unknown keys of the auth_hosts entries of
+// a config file, mapstructure would silently drop them
+func hostAuthKeyProblems(hosts interface{}, origin string) []string {
+	var entries []map[string]interface{}
+	switch h := hosts.(type) {
+	case []map[string]interface{}:
+		entries = h
+	case []interface{}:
+		for _, e := range h {
+			if m, ok := e.(map[string]interface{}); ok {
+				entries = append(entries, m)
+			}
+		}
+	}
+	var problems []string
+	known := auth.HostAuthKeys()
+	isKnown := map[string]bool{}
+	for _, k := range known {
+		isKnown[k] = true
+	}
+	for i, e := range entries {
+		for _, key := range sortedKeys(e) {
+			if !isKnown[key] {
+				problems = append(problems, fmt.Sprintf("unknown config key %s in auth_hosts entry %d in %s", unknownKeyHint(key, known), i+1, origin))
+			}
+		}
+	}
+	return problems
+}
+
+// unknownKeyHint returns the unknown key with the one of known keys it was
+// probably meant to be if they only differ in case, dashes or underscores
+func unknownKeyHint(key string, known []string) string {
+	normalize := strings.NewReplacer("_", "", "-", "")
+	for _, k := range known {
+		if normalize.Replace(k) == normalize.Replace(key) {
+			return fmt.Sprintf("%s (did you mean %s?)", key, k)
+		}
+	}
+	return key
+}
+
+// Validate checks the config for values that would make groomba fail late or
+// damage branches and returns a ConfigValidationError with every problem found
+func (c *Config) Validate() error {
+	var problems []string
+	if c.Prefix == "" {
+		problems = append(problems, "prefix must not be empty, stale branches would be copied onto themselves and deleted")
+	} else if plumbing.NewBranchReferenceName(c.Prefix+"foo").Validate() != nil {
+		problems = append(problems, fmt.Sprintf("prefix %q makes invalid branch names like %sfoo, see git help check-ref-format for the allowed characters", c.Prefix, c.Prefix))
+	}
+
+	if c.StaleAgeThreshold < 1 {
+		problems = append(problems, fmt.Sprintf("stale_age_threshold must be at least 1 day, got %d", c.StaleAgeThreshold))
+	}
+	if c.DeleteAgeThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("delete_age_threshold must be 0 to disable deleting or a number of days, got %d", c.DeleteAgeThreshold))
+	} else if c.DeleteAgeThreshold > 0 && c.DeleteAgeThreshold <= c.StaleAgeThreshold {
+		problems = append(problems, fmt.Sprintf("delete_age_threshold (%d) must be greater than stale_age_threshold (%d), otherwise stale branches are deleted without being moved first", c.DeleteAgeThreshold, c.StaleAgeThreshold))
+	}
+	if c.PurgeAgeThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("purge_age_threshold must be 0 to disable purging or a number of days, got %d", c.PurgeAgeThreshold))
+	} else if c.PurgeAgeThreshold > 0 && c.PurgeAgeThreshold <= c.StaleAgeThreshold {
+		problems = append(problems, fmt.Sprintf("purge_age_threshold (%d) must be greater than stale_age_threshold (%d), otherwise moved branches are purged on the next run", c.PurgeAgeThreshold, c.StaleAgeThreshold))
+	}
+
+	for _, remote := range c.remotes() {
+		if remote == "" {
+			problems = append(problems, "remote names must not be empty")
+		} else if plumbing.NewRemoteReferenceName(remote, "foo").Validate() != nil {
+			problems = append(problems, fmt.Sprintf("remote %q is not a valid remote name", remote))
+		}
+	}
+
+	patterns := map[string]pattern{}
+	for _, pk := range []struct {
+		key      string
+		patterns []string
+	}{
+		{"exclude_patterns", c.ExcludePatterns},
+		{"include_patterns", c.IncludePatterns},
+		{"static_branches", c.StaticBranches},
+	} {
+		for _, p := range pk.patterns {
+			compiled, err := compilePattern(p)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("invalid %s: %s", pk.key, err))
+				continue
+			}
+			patterns[p] = compiled
+		}
+	}
+	c.patterns = patterns
+
+	if c.ProxyURL != "" {
+		if _, err := url.Parse(c.ProxyURL); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid proxy_url: %s", err))
+		}
+	}
+
+	problems = append(problems, auth.DefinitionProblems(auth.Definition{Type: c.Auth, Options: c.Options}, c.AuthHosts)...)
+	problems = append(problems, c.keyProblems...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ConfigValidationError{problems: problems}
+}
+
+// Origin returns where the value of the config key was set: the path of a
+// config file, "manifest", the environment variable, the flag or "default"
+func (c *Config) Origin(key string) string {
+	if origin, ok := c.origins[key]; ok {
+		return origin
+	}
+	return "default"
+}
+
+// Print prints the effective config as YAML, with origin the origin of each
+// value is added as a comment
+func (c *Config) Print(origin bool) error {
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return err
+	}
+	if origin {
+		// the content of a mapping node alternates between keys and values,
+		// comments of empty values are only printed on the value and those of
+		// block sequences only on the key
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			key, value := doc.Content[i], doc.Content[i+1]
+			if len(value.Content) > 0 {
+				key.LineComment = c.Origin(key.Value)
+			} else {
+				value.LineComment = c.Origin(key.Value)
+			}
+		}
+	}
+	b, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// Resolver returns the auth resolver using the auth definition of the first
+// matching AuthHosts entry for each remote url, or Auth and its options
+func (c *Config) Resolver() (*auth.Resolver, error) {
+	return auth.NewResolver(auth.Definition{Type: c.Auth, Options: c.Options}, c.AuthHosts)
+}
+
+// remotes returns the names of all remotes to groom
+func (c *Config) remotes() []string {
+	if len(c.Remotes) > 0 {
+		return c.Remotes
+	}
+	return []string{c.Remote}
+}
@@ -1,14 +1,34 @@
 package groomba
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/avbm/groomba/auth"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain points the system and user config directories at empty temporary
+// directories so config files of the machine running the tests are not loaded
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "groomba-config")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "system"))
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "user"))
+	os.Unsetenv(configFileEnv)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestConfig(t *testing.T) {
 	cfg, err := GetConfig(".")
 	if err != nil {
@@ -18,10 +38,17 @@ func TestConfig(t *testing.T) {
 	t.Run("Default configs should load correctly", func(t *testing.T) {
 		a := assert.New(t)
 		a.Equal(auth.DefaultAuth, cfg.Auth)
+		a.Equal(false, cfg.AutoRevive)
 		a.Equal(false, cfg.Clobber)
+		a.Equal(0, cfg.DeleteAgeThreshold)
 		a.Equal(false, cfg.DryRun)
+		a.Equal([]string{"/revert.*/", "/cherry-pick.*/"}, cfg.ExcludePatterns)
+		a.Equal([]string{}, cfg.IncludePatterns)
 		a.Equal(uint8(4), cfg.MaxConcurrency)
 		a.Equal("stale/", cfg.Prefix)
+		a.Equal(true, cfg.ProtectDefaultBranch)
+		a.Equal(0, cfg.PurgeAgeThreshold)
+		a.Equal("origin", cfg.Remote)
 		a.Equal(14, cfg.StaleAgeThreshold)
 		a.Equal([]string{"main", "master", "production"}, cfg.StaticBranches)
 	})
@@ -42,7 +69,7 @@ func TestConfig(t *testing.T) {
 		a.Equal([]string{"main", "teststatic"}, cfg.StaticBranches)
 	})
 
-	os.Setenv("GROOMBA_AUTH", "env-auth")
+	os.Setenv("GROOMBA_AUTH", "netrc")
 	os.Setenv("GROOMBA_CLOBBER", "false")
 	os.Setenv("GROOMBA_DRY_RUN", "false")
 	os.Setenv("GROOMBA_MAX_CONCURRENCY", "2")
@@ -56,7 +83,7 @@ func TestConfig(t *testing.T) {
 	}
 	t.Run("Configs from Environment should override .groomba.yaml and defaults", func(t *testing.T) {
 		a := assert.New(t)
-		a.Equal("env-auth", string(cfg.Auth))
+		a.Equal(auth.NetrcAuth, cfg.Auth)
 		a.Equal(false, cfg.Clobber)
 		a.Equal(false, cfg.DryRun)
 		a.Equal(uint8(2), cfg.MaxConcurrency)
@@ -77,3 +104,328 @@ func TestConfig(t *testing.T) {
 	})
 
 }
+
+func TestBindFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("stale-age-threshold", 0, "")
+	flags.StringSlice("static-branches", nil, "")
+	flags.Bool("unrelated-flag", false, "")
+	// unbind flags so they do not override configs in other tests
+	t.Cleanup(func() { BindFlags(nil) })
+
+	t.Setenv("GROOMBA_STALE_AGE_THRESHOLD", "7")
+	t.Setenv("GROOMBA_STATIC_BRANCHES", "main,master")
+	err := flags.Parse([]string{"--stale-age-threshold=30", "--static-branches=develop,release", "--unrelated-flag"})
+	assert.Nil(t, err)
+	BindFlags(flags)
+
+	cfg, err := GetConfig("testdata")
+	assert.Nil(t, err)
+	t.Run("Configs from flags should override environment, .groomba.yaml and defaults", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(30, cfg.StaleAgeThreshold)
+		a.Equal([]string{"develop", "release"}, cfg.StaticBranches)
+	})
+}
+
+func TestConfigInvalidStaticBranches(t *testing.T) {
+	t.Setenv("GROOMBA_STATIC_BRANCHES", "main,release-[1")
+	_, err := GetConfig("testdata")
+	assert.EqualError(t, err, "getConfig: invalid static_branches: invalid glob pattern release-[1: syntax error in pattern")
+}
+
+func TestConfigInvalidIncludePatterns(t *testing.T) {
+	t.Setenv("GROOMBA_INCLUDE_PATTERNS", "feature/*,/feature-(/")
+	_, err := GetConfig("testdata")
+	assert.EqualError(t, err, "getConfig: invalid include_patterns: invalid regex pattern /feature-(/: error parsing regexp: missing closing ): `^(?:feature-()$`")
+}
+
+func TestConfigAuthOptions(t *testing.T) {
+	t.Setenv("GROOMBA_TOKEN_ENV", "CI_TOKEN")
+	t.Setenv("GROOMBA_TOKEN_USERNAME", "oauth2")
+	t.Setenv("GROOMBA_NETRC_FILE", "/etc/groomba/netrc")
+	cfg, err := GetConfig("testdata")
+	assert.Nil(t, err)
+	t.Run("Auth options should be loaded into the embedded auth.Options", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal("CI_TOKEN", cfg.TokenEnv)
+		a.Equal("", cfg.TokenFile)
+		a.Equal("oauth2", cfg.TokenUsername)
+		a.Equal("/etc/groomba/netrc", cfg.NetrcFile)
+	})
+}
+
+func TestConfigAuthHosts(t *testing.T) {
+	// auth_hosts can't be set as an environment variable so set it like a config file would
+	cfg, err := NewConfigLoader().fromDir("testdata", map[string]interface{}{
+		"auth_hosts": []map[string]interface{}{
+			{"host": "github.com", "auth": "token", "token_env": "GITHUB_TOKEN"},
+			{"url_prefix": "https://git.example.com/team/", "auth": "netrc", "netrc_file": "/etc/groomba/netrc"},
+		},
+	})
+	assert.Nil(t, err)
+	t.Run("auth_hosts should be loaded with their auth options", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal([]auth.HostAuth{
+			{Host: "github.com", Definition: auth.Definition{Type: auth.TokenAuth, Options: auth.Options{TokenEnv: "GITHUB_TOKEN"}}},
+			{URLPrefix: "https://git.example.com/team/", Definition: auth.Definition{Type: auth.NetrcAuth, Options: auth.Options{NetrcFile: "/etc/groomba/netrc"}}},
+		}, cfg.AuthHosts)
+	})
+
+	t.Run("unknown keys of auth_hosts entries should be reported", func(t *testing.T) {
+		a := assert.New(t)
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, ".groomba.yaml"), []byte("auth_hosts:\n  - host: github.com\n    auth: token\n    tokn_env: GITHUB_TOKEN\n"), 0644)
+		a.Nil(err)
+		_, err = NewConfigLoader().Load(dir)
+		var validationErr *ConfigValidationError
+		if a.True(errors.As(err, &validationErr)) {
+			a.Equal([]string{
+				"auth_hosts entry 1: auth token needs token_env or token_file to be set",
+				"unknown config key tokn_env in auth_hosts entry 1 in " + filepath.Join(dir, ".groomba.yaml"),
+			}, validationErr.Problems())
+		}
+
+		_, err = NewConfigLoader().fromDir(dir, map[string]interface{}{
+			"auth_hosts": []map[string]interface{}{{"host": "github.com", "auth": "token", "token-env": "GITHUB_TOKEN"}},
+		})
+		if a.NotNil(err) {
+			a.Contains(err.Error(), "unknown config key token-env (did you mean token_env?) in auth_hosts entry 1 in manifest")
+		}
+	})
+}
+
+func TestConfigLoader(t *testing.T) {
+	// empty variables are ignored so only config files and flags apply
+	t.Setenv("GROOMBA_PREFIX", "")
+	t.Setenv("GROOMBA_STALE_AGE_THRESHOLD", "")
+
+	t.Run("Configs loaded one after another should not share settings", func(t *testing.T) {
+		a := assert.New(t)
+		l := NewConfigLoader()
+		cfg, err := l.Load("testdata/ssh-agent")
+		a.Nil(err)
+		a.Equal("zzz_", cfg.Prefix)
+		cfg, err = l.Load(".")
+		a.Nil(err)
+		a.Equal("stale/", cfg.Prefix)
+		a.Equal(14, cfg.StaleAgeThreshold)
+	})
+
+	t.Run("Flags should only apply to the loader they are bound to", func(t *testing.T) {
+		a := assert.New(t)
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("prefix", "", "")
+		a.Nil(flags.Parse([]string{"--prefix=flag/"}))
+		l := NewConfigLoader()
+		l.BindFlags(flags)
+
+		cfg, err := l.Load("testdata/ssh-agent")
+		a.Nil(err)
+		a.Equal("flag/", cfg.Prefix)
+		cfg, err = NewConfigLoader().Load("testdata/ssh-agent")
+		a.Nil(err)
+		a.Equal("zzz_", cfg.Prefix)
+	})
+
+	t.Run("Configs should load concurrently", func(t *testing.T) {
+		l := NewConfigLoader()
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			for dir, prefix := range map[string]string{"testdata/ssh-agent": "zzz_", ".": "stale/"} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					cfg, err := l.Load(dir)
+					if assert.Nil(t, err) {
+						assert.Equal(t, prefix, cfg.Prefix)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{Auth: auth.DefaultAuth, Prefix: "stale/", Remote: "origin", StaleAgeThreshold: 14}
+	}
+	assert.Nil(t, valid().Validate())
+
+	for name, tc := range map[string]struct {
+		update   func(c *Config)
+		problems []string
+	}{
+		"empty prefix": {
+			func(c *Config) { c.Prefix = "" },
+			[]string{"prefix must not be empty, stale branches would be copied onto themselves and deleted"},
+		},
+		"prefix with spaces": {
+			func(c *Config) { c.Prefix = "old branches/" },
+			[]string{`prefix "old branches/" makes invalid branch names like old branches/foo, see git help check-ref-format for the allowed characters`},
+		},
+		"prefix with dots": {
+			func(c *Config) { c.Prefix = "../" },
+			[]string{`prefix "../" makes invalid branch names like ../foo, see git help check-ref-format for the allowed characters`},
+		},
+		"thresholds": {
+			func(c *Config) { c.StaleAgeThreshold, c.DeleteAgeThreshold, c.PurgeAgeThreshold = -1, -1, -1 },
+			[]string{
+				"stale_age_threshold must be at least 1 day, got -1",
+				"delete_age_threshold must be 0 to disable deleting or a number of days, got -1",
+				"purge_age_threshold must be 0 to disable purging or a number of days, got -1",
+			},
+		},
+		"delete before stale": {
+			func(c *Config) { c.DeleteAgeThreshold = 7 },
+			[]string{"delete_age_threshold (7) must be greater than stale_age_threshold (14), otherwise stale branches are deleted without being moved first"},
+		},
+		"purge before stale": {
+			func(c *Config) { c.PurgeAgeThreshold = 14 },
+			[]string{"purge_age_threshold (14) must be greater than stale_age_threshold (14), otherwise moved branches are purged on the next run"},
+		},
+		"auth options": {
+			func(c *Config) {
+				c.Auth = auth.TokenAuth
+				c.AuthHosts = []auth.HostAuth{
+					{Host: "github.com", Definition: auth.Definition{Type: auth.SSHKeyAuth}},
+					{Definition: auth.Definition{Type: "oauth"}},
+					{Host: "[", Definition: auth.Definition{Type: auth.TokenAuth, Options: auth.Options{TokenFile: "/run/token"}}},
+				}
+			},
+			[]string{
+				"auth token needs token_env or token_file to be set",
+				"auth_hosts entry 1: auth ssh-key needs ssh_key_file to be set",
+				"auth_hosts entry 2 with auth oauth must set host or url_prefix",
+				"auth_hosts entry 2: auth type oauth not supported. valid values: auto, credential-helper, default, netrc, ssh-agent, ssh-key, token",
+				"auth_hosts entry 3 has invalid host pattern [: syntax error in pattern",
+			},
+		},
+		"remotes": {
+			func(c *Config) { c.Remotes = []string{"origin", "", "my remote"} },
+			[]string{"remote names must not be empty", `remote "my remote" is not a valid remote name`},
+		},
+		"every problem at once": {
+			func(c *Config) {
+				c.Prefix, c.StaleAgeThreshold, c.StaticBranches = "", 0, []string{"release-[1"}
+				c.keyProblems = []string{"unknown config key colour in .groomba.yaml"}
+			},
+			[]string{
+				"prefix must not be empty, stale branches would be copied onto themselves and deleted",
+				"stale_age_threshold must be at least 1 day, got 0",
+				"invalid static_branches: invalid glob pattern release-[1: syntax error in pattern",
+				"unknown config key colour in .groomba.yaml",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := valid()
+			tc.update(c)
+			var e *ConfigValidationError
+			if assert.True(t, errors.As(c.Validate(), &e)) {
+				assert.Equal(t, tc.problems, e.Problems())
+			}
+		})
+	}
+}
+
+func TestConfigUnknownKeys(t *testing.T) {
+	t.Setenv("GROOMBA_PREFIX", "")
+	t.Setenv("GROOMBA_STALE_AGE_THRESHOLD", "")
+	t.Setenv("GROOMBA_STATIC_BRANCHES", "")
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".groomba.toml"), []byte("StaleAgeThreshold = 20\nprefix = \"old/\"\ncolour = \"blue\"\n"), 0644)
+	assert.Nil(t, err)
+
+	_, err = NewConfigLoader().Load(dir)
+	file := filepath.Join(dir, ".groomba.toml")
+	assert.EqualError(t, err, fmt.Sprintf("getConfig: unknown config key colour in %s\nunknown config key staleagethreshold (did you mean stale_age_threshold?) in %s", file, file))
+
+	err = os.WriteFile(filepath.Join(dir, ".groomba.toml"), []byte("stale_age_threshold = 20\nprefix = \"old/\"\n"), 0644)
+	assert.Nil(t, err)
+	cfg, err := NewConfigLoader().Load(dir)
+	assert.Nil(t, err)
+	if cfg != nil {
+		assert.Equal(t, 20, cfg.StaleAgeThreshold)
+	}
+}
+
+func TestConfigDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	write := func(file, content string) string {
+		file = filepath.Join(dir, file)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.Nil(t, os.WriteFile(file, []byte(content), 0644))
+		return file
+	}
+	system := write("system/groomba/config.toml", "clobber = true\nprefix = \"system/\"\nstale_age_threshold = 20\n")
+	user := write("user/groomba/config.yaml", "prefix: user/\nstale_age_threshold: 30\n")
+	top := write("repo/.groomba.yaml", "prefix: top/\ndelete_age_threshold: 60\n")
+	sub := write("repo/sub/.groomba.yaml", "prefix: sub/\n")
+	explicit := write("explicit.yaml", "prefix: explicit/\n")
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo", ".git"), 0755))
+
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(dir, "system"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "user"))
+	t.Setenv("GROOMBA_CONFIG", "")
+	t.Setenv("GROOMBA_CLOBBER", "")
+	t.Setenv("GROOMBA_DELETE_AGE_THRESHOLD", "")
+	t.Setenv("GROOMBA_PREFIX", "")
+	t.Setenv("GROOMBA_STALE_AGE_THRESHOLD", "")
+
+	t.Run("config files should be merged with the closest taking precedence", func(t *testing.T) {
+		a := assert.New(t)
+		cfg, err := NewConfigLoader().Load(filepath.Join(dir, "repo", "sub"))
+		if !a.Nil(err) {
+			return
+		}
+		a.Equal(true, cfg.Clobber)
+		a.Equal(system, cfg.Origin("clobber"))
+		a.Equal(30, cfg.StaleAgeThreshold)
+		a.Equal(user, cfg.Origin("stale_age_threshold"))
+		a.Equal(60, cfg.DeleteAgeThreshold)
+		a.Equal(top, cfg.Origin("delete_age_threshold"))
+		a.Equal("sub/", cfg.Prefix)
+		a.Equal(sub, cfg.Origin("prefix"))
+		a.Equal("default", cfg.Origin("remote"))
+	})
+
+	t.Run("explicit config file should take precedence over discovered ones", func(t *testing.T) {
+		a := assert.New(t)
+		l := NewConfigLoader()
+		l.SetConfigFile(explicit)
+		cfg, err := l.Load(filepath.Join(dir, "repo", "sub"))
+		if a.Nil(err) {
+			a.Equal("explicit/", cfg.Prefix)
+			a.Equal(explicit, cfg.Origin("prefix"))
+		}
+
+		t.Setenv("GROOMBA_CONFIG", explicit)
+		cfg, err = NewConfigLoader().Load(filepath.Join(dir, "repo"))
+		if a.Nil(err) {
+			a.Equal("explicit/", cfg.Prefix)
+		}
+
+		t.Setenv("GROOMBA_CONFIG", explicit+".missing.yaml")
+		_, err = NewConfigLoader().Load(filepath.Join(dir, "repo"))
+		a.EqualError(err, fmt.Sprintf("getConfig: failed to read in config: open %s.missing.yaml: no such file or directory", explicit))
+	})
+
+	t.Run("environment variables and flags should take precedence over config files", func(t *testing.T) {
+		a := assert.New(t)
+		t.Setenv("GROOMBA_PREFIX", "env/")
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.Int("stale-age-threshold", 0, "")
+		a.Nil(flags.Parse([]string{"--stale-age-threshold=40"}))
+		l := NewConfigLoader()
+		l.BindFlags(flags)
+		cfg, err := l.Load(filepath.Join(dir, "repo"))
+		if a.Nil(err) {
+			a.Equal("env/", cfg.Prefix)
+			a.Equal("environment variable GROOMBA_PREFIX", cfg.Origin("prefix"))
+			a.Equal(40, cfg.StaleAgeThreshold)
+			a.Equal("flag --stale-age-threshold", cfg.Origin("stale_age_threshold"))
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package groomba
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/viper"
+)
+
+// configLayer is a config file merged into the config, later layers take
+// precedence over earlier ones
+type configLayer struct {
+	// origin is where the file was read from, usually its path
+	origin     string
+	configType string
+	contents   string
+	// policyOnly layers may only set the policyKeys
+	policyOnly bool
+}
+
+// configFileEnv is the environment variable holding the path of an explicit
+// config file, the --config flag takes precedence over it
+const configFileEnv = "GROOMBA_CONFIG"
+
+// SetConfigFile sets the path of an explicit config file that takes
+// precedence over all discovered config files, like --config does
+func (l *ConfigLoader) SetConfigFile(file string) {
+	l.configFile = file
+}
+
+// explicitConfigFile returns the path set with SetConfigFile, the --config
+// flag or GROOMBA_CONFIG in that order
+func (l *ConfigLoader) explicitConfigFile() string {
+	if l.configFile != "" {
+		return l.configFile
+	}
+	if l.flags != nil {
+		if f := l.flags.Lookup("config"); f != nil && f.Changed {
+			return f.Value.String()
+		}
+	}
+	return os.Getenv(configFileEnv)
+}
+
+// dirLayers returns the config layers for the repository checked out at dir:
+// the system and user configs, the .groomba config file at the top level of
+// the git repository and in dir, and the explicit config file
+func (l *ConfigLoader) dirLayers(dir string) ([]configLayer, error) {
+	layers, err := userLayers()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{dir}
+	if top, ok := gitTopLevel(dir); ok {
+		if abs, err := filepath.Abs(dir); err != nil || abs != top {
+			dirs = []string{top, dir}
+		}
+	}
+	for _, d := range dirs {
+		layer, ok, err := findConfigFile(filepath.Join(d, ".groomba"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			layers = append(layers, layer)
+		}
+	}
+	return l.withExplicitLayer(layers)
+}
+
+// treeLayers returns the config layers like dirLayers but with the .groomba
+// config file in the root of tree instead of the ones in the checkout. Anyone
+// who can push to the default branch can change that file, so it may only
+// set the policyKeys
+func (l *ConfigLoader) treeLayers(tree *object.Tree) ([]configLayer, error) {
+	layers, err := userLayers()
+	if err != nil {
+		return nil, err
+	}
+	if tree != nil {
+		for _, ext := range viper.SupportedExts {
+			f, err := tree.File(".groomba." + ext)
+			if err != nil {
+				continue
+			}
+			contents, err := f.Contents()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", f.Name, err)
+			}
+			layers = append(layers, configLayer{origin: f.Name + " (default branch)", configType: ext, contents: contents, policyOnly: true})
+			break
+		}
+	}
+	return l.withExplicitLayer(layers)
+}
+
+// withExplicitLayer appends the explicit config file to layers if one is set,
+// it must exist
+func (l *ConfigLoader) withExplicitLayer(layers []configLayer) ([]configLayer, error) {
+	file := l.explicitConfigFile()
+	if file == "" {
+		return layers, nil
+	}
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	if !isSupportedExt(ext) {
+		return nil, fmt.Errorf("config file %s must have one of the extensions %s", file, strings.Join(viper.SupportedExts, ", "))
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, configLayer{origin: file, configType: ext, contents: string(b)}), nil
+}
+
+// userLayers returns the system wide config files in $XDG_CONFIG_DIRS, by
+// default /etc/xdg, followed by the config file of the user in
+// $XDG_CONFIG_HOME, by default ~/.config. Each is named groomba/config.<ext>
+func userLayers() ([]configLayer, error) {
+	systemDirs := filepath.SplitList(os.Getenv("XDG_CONFIG_DIRS"))
+	if len(systemDirs) == 0 {
+		systemDirs = []string{"/etc/xdg"}
+	}
+	// the first directory of XDG_CONFIG_DIRS is the most important
+	var dirs []string
+	for i := len(systemDirs) - 1; i >= 0; i-- {
+		dirs = append(dirs, systemDirs[i])
+	}
+	if userDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, userDir)
+	}
+
+	var layers []configLayer
+	for _, d := range dirs {
+		if !filepath.IsAbs(d) {
+			continue
+		}
+		layer, ok, err := findConfigFile(filepath.Join(d, "groomba", "config"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			layers = append(layers, layer)
+		}
+	}
+	return layers, nil
+}
+
+// findConfigFile returns the first file named base.<ext> for the supported
+// extensions of viper, its origin is the absolute path of the file
+func findConfigFile(base string) (configLayer, bool, error) {
+	if abs, err := filepath.Abs(base); err == nil {
+		base = abs
+	}
+	for _, ext := range viper.SupportedExts {
+		file := base + "." + ext
+		b, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return configLayer{}, false, err
+		}
+		return configLayer{origin: file, configType: ext, contents: string(b)}, true, nil
+	}
+	return configLayer{}, false, nil
+}
+
+// gitTopLevel returns the absolute path of the top level directory of the git
+// repository containing dir
+func gitTopLevel(dir string) (string, bool) {
+	d, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d, true
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", false
+		}
+		d = parent
+	}
+}
+
+// isSupportedExt returns true if viper can read config files with extension ext
+func isSupportedExt(ext string) bool {
+	for _, e := range viper.SupportedExts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,7 @@ package groomba
 */
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -30,8 +31,10 @@ const (
 	DeleteBranch
 )
 
-// MoveBranchError defines the errors during the MoveBranch step
+// MoveBranchError defines the errors during the MoveBranch step, remote is
+// only set when grooming multiple remotes
 type MoveBranchError struct {
+	remote    string
 	branch    string
 	operation MoveBranchOperation
 	err       error
@@ -39,10 +42,14 @@ type MoveBranchError struct {
 
 // Error so MoveBranchError satisfies the error interface
 func (e *MoveBranchError) Error() string {
-	if e.operation == CopyBranch {
-		return fmt.Sprintf("branch: %s failed on operation copy with error: %s", e.branch, e.err)
+	op := "copy"
+	if e.operation != CopyBranch {
+		op = "delete"
 	}
-	return fmt.Sprintf("branch: %s failed on operation delete with error: %s", e.branch, e.err)
+	if e.remote != "" {
+		return fmt.Sprintf("remote: %s branch: %s failed on operation %s with error: %s", e.remote, e.branch, op, e.err)
+	}
+	return fmt.Sprintf("branch: %s failed on operation %s with error: %s", e.branch, op, e.err)
 }
 
 // Unwrap for MoveBranchError
@@ -64,3 +71,148 @@ func (m *MoveStaleBranchesError) Error() string {
 	sort.Strings(msgList)
 	return strings.Join(msgList, "\n")
 }
+
+// newMoveStaleBranchesError returns a MoveStaleBranchesError for all
+// MoveBranchErrors in errList or nil if there are none
+func newMoveStaleBranchesError(errList []error) error {
+	m := &MoveStaleBranchesError{}
+	for _, err := range errList {
+		var e *MoveBranchError
+		if errors.As(err, &e) {
+			m.errList = append(m.errList, *e)
+		}
+	}
+	if len(m.errList) == 0 {
+		return nil
+	}
+	return m
+}
+
+// ReviveBranchError defines the errors during the ReviveBranch step, remote
+// is only set when grooming multiple remotes
+type ReviveBranchError struct {
+	remote    string
+	branch    string
+	operation MoveBranchOperation
+	err       error
+}
+
+// Error so ReviveBranchError satisfies the error interface
+func (e *ReviveBranchError) Error() string {
+	op := "copy"
+	if e.operation != CopyBranch {
+		op = "delete"
+	}
+	if e.remote != "" {
+		return fmt.Sprintf("remote: %s branch: %s failed to revive on operation %s with error: %s", e.remote, e.branch, op, e.err)
+	}
+	return fmt.Sprintf("branch: %s failed to revive on operation %s with error: %s", e.branch, op, e.err)
+}
+
+// Unwrap for ReviveBranchError
+func (e *ReviveBranchError) Unwrap() error {
+	return e.err
+}
+
+// ReviveBranchesError stores all errors from ReviveBranches
+type ReviveBranchesError struct {
+	errList []ReviveBranchError
+}
+
+// Error so ReviveBranchesError satisfies the error interface
+func (r *ReviveBranchesError) Error() string {
+	msgList := []string{}
+	for _, err := range r.errList {
+		msgList = append(msgList, err.Error())
+	}
+	sort.Strings(msgList)
+	return strings.Join(msgList, "\n")
+}
+
+// newReviveBranchesError returns a ReviveBranchesError for all
+// ReviveBranchErrors in errList or nil if there are none
+func newReviveBranchesError(errList []error) error {
+	r := &ReviveBranchesError{}
+	for _, err := range errList {
+		var e *ReviveBranchError
+		if errors.As(err, &e) {
+			r.errList = append(r.errList, *e)
+		}
+	}
+	if len(r.errList) == 0 {
+		return nil
+	}
+	return r
+}
+
+// RepositoryError defines the error of grooming one repository of a manifest
+type RepositoryError struct {
+	repository string
+	err        error
+}
+
+// Error so RepositoryError satisfies the error interface
+func (e *RepositoryError) Error() string {
+	return fmt.Sprintf("repository: %s failed with error: %s", e.repository, e.err)
+}
+
+// Unwrap for RepositoryError, the MoveStaleBranchesError and
+// ReviveBranchesError of the repository can be found with errors.As
+func (e *RepositoryError) Unwrap() error {
+	return e.err
+}
+
+// RunAllError stores the errors of all repositories that failed in RunAll
+type RunAllError struct {
+	errList []RepositoryError
+}
+
+// Error so RunAllError satisfies the error interface
+func (r *RunAllError) Error() string {
+	msgList := []string{}
+	for _, err := range r.errList {
+		msgList = append(msgList, err.Error())
+	}
+	sort.Strings(msgList)
+	return strings.Join(msgList, "\n")
+}
+
+// Unwrap for RunAllError returns the RepositoryErrors of all repositories
+func (r *RunAllError) Unwrap() []error {
+	errs := []error{}
+	for i := range r.errList {
+		errs = append(errs, &r.errList[i])
+	}
+	return errs
+}
+
+// newRunAllError returns a RunAllError for all RepositoryErrors in errList or
+// nil if there are none
+func newRunAllError(errList []error) error {
+	r := &RunAllError{}
+	for _, err := range errList {
+		var e *RepositoryError
+		if errors.As(err, &e) {
+			r.errList = append(r.errList, *e)
+		}
+	}
+	if len(r.errList) == 0 {
+		return nil
+	}
+	return r
+}
+
+// ConfigValidationError lists every problem found by Config.Validate
+type ConfigValidationError struct {
+	problems []string
+}
+
+// Error so ConfigValidationError satisfies the error interface
+func (e *ConfigValidationError) Error() string {
+	return strings.Join(e.problems, "\n")
+}
+
+// Problems returns the problems found in the config
+func (e *ConfigValidationError) Problems() []string {
+	return e.problems
+}
@@ -20,6 +20,12 @@ func TestMoveBranchError(t *testing.T) {
 		expectedErrMsg := "branch: errBranch2 failed on operation delete with error: some other error for errBranch2"
 		a.Equal(expectedErrMsg, m.Error())
 	})
+	t.Run("MoveBranchError should include the remote when set", func(t *testing.T) {
+		a := assert.New(t)
+		m := &MoveBranchError{remote: "mirror", branch: "errBranch4", operation: DeleteBranch, err: fmt.Errorf("some error for errBranch4")}
+		expectedErrMsg := "remote: mirror branch: errBranch4 failed on operation delete with error: some error for errBranch4"
+		a.Equal(expectedErrMsg, m.Error())
+	})
 	t.Run("MoveBranchError should return expected error output and have copy operation as default", func(t *testing.T) {
 		a := assert.New(t)
 		m := &MoveBranchError{branch: "errBranch3", err: fmt.Errorf("some more errors for errBranch3")}
@@ -44,3 +50,33 @@ func TestMoveStaleBranchesError(t *testing.T) {
 		a.Equal(expectedErrMsg, m.Error())
 	})
 }
+
+func TestReviveBranchError(t *testing.T) {
+	t.Run("ReviveBranchError should return expected error output copy operation", func(t *testing.T) {
+		a := assert.New(t)
+		r := &ReviveBranchError{branch: "errBranch1", operation: CopyBranch, err: fmt.Errorf("some error for errBranch1")}
+		expectedErrMsg := "branch: errBranch1 failed to revive on operation copy with error: some error for errBranch1"
+		a.Equal(expectedErrMsg, r.Error())
+	})
+	t.Run("ReviveBranchError should return expected error output delete operation", func(t *testing.T) {
+		a := assert.New(t)
+		r := &ReviveBranchError{branch: "errBranch2", operation: DeleteBranch, err: fmt.Errorf("some other error for errBranch2")}
+		expectedErrMsg := "branch: errBranch2 failed to revive on operation delete with error: some other error for errBranch2"
+		a.Equal(expectedErrMsg, r.Error())
+	})
+}
+
+func TestReviveBranchesError(t *testing.T) {
+	errList := []ReviveBranchError{}
+	errList = append(errList, ReviveBranchError{branch: "errBranch2", operation: DeleteBranch, err: fmt.Errorf("some other error for errBranch2")})
+	errList = append(errList, ReviveBranchError{branch: "errBranch1", operation: CopyBranch, err: fmt.Errorf("some error for errBranch1")})
+
+	expectedErrMsg := "branch: errBranch1 failed to revive on operation copy with error: some error for errBranch1\n" +
+		"branch: errBranch2 failed to revive on operation delete with error: some other error for errBranch2"
+
+	r := ReviveBranchesError{errList: errList}
+	t.Run("ReviveBranchesError should return the expected sorted and joined output", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(expectedErrMsg, r.Error())
+	})
+}
@@ -17,6 +17,7 @@ package groomba
 */
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -26,7 +27,11 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
 
 	"gopkg.in/yaml.v3"
 )
@@ -47,8 +52,17 @@ func CheckIfError(err error, message ...string) {
 	log.Fatalf("%s %s", message, err)
 }
 
+// Authenticator returns the auth method to use for the remote with url
 type Authenticator interface {
-	Get() transport.AuthMethod
+	Get(url string) (transport.AuthMethod, error)
+}
+
+// CredentialReporter is implemented by Authenticators that want to know if
+// the remote with url accepted the credentials returned by Get, eg to store
+// or erase them in a git credential helper
+type CredentialReporter interface {
+	Approve(url string) error
+	Reject(url string) error
 }
 
 func NewGroomba(config *Config, repo *git.Repository, a Authenticator) Groomba {
@@ -59,16 +73,382 @@ func NewGroomba(config *Config, repo *git.Repository, a Authenticator) Groomba {
 	}
 }
 
-func (g Groomba) IsStaticBranch(name string) bool {
-	for _, b := range g.cfg.StaticBranches {
-		if fmt.Sprintf("refs/remotes/origin/%s", b) == name {
-			return true
+// Config returns the config of g
+func (g Groomba) Config() *Config {
+	return g.cfg
+}
+
+// NewMemoryRepository returns an in-memory repository without a worktree
+// whose only remote is remote pointing at url, so the branches of url can be
+// groomed without a local checkout
+func NewMemoryRepository(remote, url string) (*git.Repository, error) {
+	s := memory.NewStorage()
+	repo, err := git.Init(&memoryStorage{Storage: s, lockedReferences: &lockedReferences{refs: s}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: remote, URLs: []string{url}})
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// PlainOpen opens the repository at path like git.PlainOpen with references
+// that can be updated by the concurrent workers of MoveStaleBranches
+func PlainOpen(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := repo.Storer.(*filesystem.Storage); ok {
+		repo.Storer = &filesystemStorage{Storage: s, lockedReferences: &lockedReferences{refs: s}}
+	}
+	return repo, nil
+}
+
+// memoryStorage is a memory.Storage with lockedReferences, the references of
+// memory.Storage are a plain map
+type memoryStorage struct {
+	*memory.Storage
+	*lockedReferences
+}
+
+// filesystemStorage is a filesystem.Storage with lockedReferences, every push
+// updates the remote tracking references and a loose reference file read
+// while it is rewritten is empty
+type filesystemStorage struct {
+	*filesystem.Storage
+	*lockedReferences
+}
+
+// lockedReferences serializes the updates of refs with reads so references
+// can be updated concurrently, its methods take precedence over the ones
+// promoted from the embedded ReferenceStorage of the storages
+type lockedReferences struct {
+	refs storer.ReferenceStorer
+	mu   sync.RWMutex
+}
+
+func (l *lockedReferences) SetReference(ref *plumbing.Reference) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refs.SetReference(ref)
+}
+
+func (l *lockedReferences) CheckAndSetReference(ref, old *plumbing.Reference) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refs.CheckAndSetReference(ref, old)
+}
+
+func (l *lockedReferences) RemoveReference(n plumbing.ReferenceName) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refs.RemoveReference(n)
+}
+
+func (l *lockedReferences) PackRefs() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refs.PackRefs()
+}
+
+func (l *lockedReferences) Reference(n plumbing.ReferenceName) (*plumbing.Reference, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.refs.Reference(n)
+}
+
+func (l *lockedReferences) IterReferences() (storer.ReferenceIter, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.refs.IterReferences()
+}
+
+func (l *lockedReferences) CountLooseRefs() (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.refs.CountLooseRefs()
+}
+
+// remoteRefName returns the name of the remote tracking reference of branch
+// for the configured Remote, ie refs/remotes/<remote>/<branch>
+func (g Groomba) remoteRefName(branch string) plumbing.ReferenceName {
+	return plumbing.NewRemoteReferenceName(g.cfg.Remote, branch)
+}
+
+// splitRemoteRef splits the remote tracking reference name into the name of
+// one of the configured remotes and the branch name. ok is false if name is not
+// a remote tracking reference of any configured remote
+func (g Groomba) splitRemoteRef(name string) (remote, branch string, ok bool) {
+	for _, r := range g.cfg.remotes() {
+		prefix := plumbing.NewRemoteReferenceName(r, "").String()
+		// prefer the longest match in case remote names are nested
+		if strings.HasPrefix(name, prefix) && len(r) > len(remote) {
+			remote, branch, ok = r, strings.TrimPrefix(name, prefix), true
+		}
+	}
+	return remote, branch, ok
+}
+
+// onRemote returns a copy of g that operates on remote instead of Remote
+func (g Groomba) onRemote(remote string) Groomba {
+	cfg := *g.cfg
+	cfg.Remote = remote
+	g.cfg = &cfg
+	return g
+}
+
+// errRemote returns the remote to report in errors, it is only set when
+// grooming multiple remotes so single remote errors stay unchanged
+func (g Groomba) errRemote() string {
+	if len(g.cfg.remotes()) > 1 {
+		return g.cfg.Remote
+	}
+	return ""
+}
+
+// Fetch updates the remote tracking references of all branches of all
+// configured remotes. If ProtectDefaultBranch is enabled it also detects the
+// default branch of each remote so it is treated as static. Fetching warns
+// once per remote if InsecureSkipTLSVerify disables TLS verification
+func (g Groomba) Fetch() error {
+	for _, remote := range g.cfg.remotes() {
+		r := g.onRemote(remote)
+		ro, err := r.remoteOptions()
+		if err != nil {
+			return fmt.Errorf("remote %s: %w", remote, err)
+		}
+		if ro.insecureSkipTLS {
+			log.Warnf("!!! TLS certificate verification is DISABLED for remote %s since insecure_skip_tls_verify=true, connections are open to man-in-the-middle attacks !!!", remote)
 		}
+		err = g.repo.Fetch(&git.FetchOptions{
+			RemoteName:      remote,
+			RefSpecs:        []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/*:%s*", r.remoteRefName("")))},
+			Depth:           1,
+			Auth:            ro.auth,
+			CABundle:        ro.caBundle,
+			InsecureSkipTLS: ro.insecureSkipTLS,
+			ProxyOptions:    ro.proxy,
+		})
+		if err == git.NoErrAlreadyUpToDate {
+			err = nil
+		}
+		g.reportCredentials(ro.url, err)
+		if err != nil {
+			return fmt.Errorf("remote %s: %w", remote, err)
+		}
+	}
+	if g.cfg.ProtectDefaultBranch {
+		return g.protectDefaultBranches()
+	}
+	return nil
+}
+
+// reportCredentials tells the Authenticator if the remote with url accepted
+// its credentials based on the error of a fetch. Errors other than auth
+// failures are not reported since they say nothing about the credentials
+func (g Groomba) reportCredentials(url string, err error) {
+	cr, ok := g.auth.(CredentialReporter)
+	if !ok {
+		return
+	}
+	switch {
+	case err == nil:
+		err = cr.Approve(url)
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		err = cr.Reject(url)
+	default:
+		return
+	}
+	if err != nil {
+		log.Warnf("failed to report credentials: %s", err)
+	}
+}
+
+// IsStaticBranch returns true if name is the remote tracking reference of a
+// branch matching any of the StaticBranches patterns on any configured remote
+// or of the default branch of its remote if ProtectDefaultBranch is enabled
+func (g Groomba) IsStaticBranch(name string) bool {
+	remote, branch, ok := g.splitRemoteRef(name)
+	if !ok {
+		return false
+	}
+	if g.cfg.matchAnyPattern(g.cfg.StaticBranches, branch) {
+		return true
+	}
+	if g.cfg.ProtectDefaultBranch {
+		defaultBranch, ok := g.defaultBranch(remote)
+		return ok && defaultBranch == branch
 	}
 	return false
 }
 
+// defaultBranch returns the default branch of remote based on the remote HEAD
+// reference, ie refs/remotes/<remote>/HEAD. ok is false if it does not exist
+func (g Groomba) defaultBranch(remote string) (branch string, ok bool) {
+	ref, err := g.repo.Reference(plumbing.NewRemoteHEADReferenceName(remote), false)
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return "", false
+	}
+	prefix := plumbing.NewRemoteReferenceName(remote, "").String()
+	if !strings.HasPrefix(ref.Target().String(), prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref.Target().String(), prefix), true
+}
+
+// DefaultBranchTree returns the tree of the tip commit of the default branch
+// of Remote, eg to read the config file when there is no local checkout. It
+// needs the references fetched by Fetch
+func (g Groomba) DefaultBranchTree() (*object.Tree, error) {
+	if err := g.setRemoteHEAD(g.cfg.Remote); err != nil {
+		return nil, fmt.Errorf("remote %s: failed to detect default branch: %w", g.cfg.Remote, err)
+	}
+	branch, ok := g.defaultBranch(g.cfg.Remote)
+	if !ok {
+		return nil, fmt.Errorf("remote %s: no default branch found", g.cfg.Remote)
+	}
+	ref, err := g.repo.Reference(g.remoteRefName(branch), true)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: default branch %s: %w", g.cfg.Remote, branch, err)
+	}
+	commit, err := g.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: default branch %s: %w", g.cfg.Remote, branch, err)
+	}
+	return commit.Tree()
+}
+
+// setRemoteHEAD creates the remote HEAD reference of remote from the HEAD
+// advertised by the remote if it does not exist yet, similar to running
+// git remote set-head <remote> --auto
+func (g Groomba) setRemoteHEAD(remote string) error {
+	if _, ok := g.defaultBranch(remote); ok {
+		return nil
+	}
+	r, err := g.repo.Remote(remote)
+	if err != nil {
+		return err
+	}
+	ro, err := g.onRemote(remote).remoteOptions()
+	if err != nil {
+		return err
+	}
+	refs, err := r.List(&git.ListOptions{
+		Auth:            ro.auth,
+		CABundle:        ro.caBundle,
+		InsecureSkipTLS: ro.insecureSkipTLS,
+		ProxyOptions:    ro.proxy,
+	})
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			target := plumbing.NewRemoteReferenceName(remote, ref.Target().Short())
+			return g.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.NewRemoteHEADReferenceName(remote), target))
+		}
+	}
+	log.Warnf("could not detect the default branch of remote %s", remote)
+	return nil
+}
+
+// protectDefaultBranches detects the default branch of each configured remote
+// and logs the default branches that are only static because of
+// ProtectDefaultBranch
+func (g Groomba) protectDefaultBranches() error {
+	for _, remote := range g.cfg.remotes() {
+		if err := g.setRemoteHEAD(remote); err != nil {
+			return fmt.Errorf("remote %s: failed to detect default branch: %w", remote, err)
+		}
+		if branch, ok := g.defaultBranch(remote); ok && !g.cfg.matchAnyPattern(g.cfg.StaticBranches, branch) {
+			log.Infof("Protecting branch %s on remote %s since it is the default branch, set protect_default_branch=false to disable", branch, remote)
+		}
+	}
+	return nil
+}
+
+// FilterBranches returns the branches older than StaleAgeThreshold that
+// should be moved. If DeleteAgeThreshold is set, branches older than it are
+// left out since they will be deleted instead, see FilterDeleteBranches
 func (g Groomba) FilterBranches(referenceDate time.Time) ([]*plumbing.Reference, error) {
+	return g.filterBranches(referenceDate, g.isActiveBranch, func(age time.Duration) bool {
+		return age > days(g.cfg.StaleAgeThreshold) &&
+			(g.cfg.DeleteAgeThreshold <= 0 || age <= days(g.cfg.DeleteAgeThreshold))
+	})
+}
+
+// FilterDeleteBranches returns the branches older than DeleteAgeThreshold
+// that should be deleted. It returns no branches if DeleteAgeThreshold is 0
+func (g Groomba) FilterDeleteBranches(referenceDate time.Time) ([]*plumbing.Reference, error) {
+	if g.cfg.DeleteAgeThreshold <= 0 {
+		return []*plumbing.Reference{}, nil
+	}
+	return g.filterBranches(referenceDate, g.isActiveBranch, func(age time.Duration) bool {
+		return age > days(g.cfg.DeleteAgeThreshold)
+	})
+}
+
+// FilterPurgeBranches returns the branches that were already moved, ie have
+// Prefix, and are older than PurgeAgeThreshold so they should be deleted. Age
+// is based on the last commit on the branch and not the time it was moved. It
+// returns no branches if PurgeAgeThreshold is 0. With AutoRevive branches
+// that FilterReviveBranches returns are never purged
+func (g Groomba) FilterPurgeBranches(referenceDate time.Time) ([]*plumbing.Reference, error) {
+	if g.cfg.PurgeAgeThreshold <= 0 {
+		return []*plumbing.Reference{}, nil
+	}
+	return g.filterBranches(referenceDate, g.isMovedBranch, func(age time.Duration) bool {
+		if g.cfg.AutoRevive && age <= days(g.cfg.StaleAgeThreshold) {
+			return false
+		}
+		return age > days(g.cfg.PurgeAgeThreshold)
+	})
+}
+
+// FilterReviveBranches returns the branches that were already moved, ie have
+// Prefix, but received new commits so they are no longer older than
+// StaleAgeThreshold. It returns no branches unless AutoRevive is enabled
+func (g Groomba) FilterReviveBranches(referenceDate time.Time) ([]*plumbing.Reference, error) {
+	if !g.cfg.AutoRevive {
+		return []*plumbing.Reference{}, nil
+	}
+	return g.filterBranches(referenceDate, g.isMovedBranch, func(age time.Duration) bool {
+		return age <= days(g.cfg.StaleAgeThreshold)
+	})
+}
+
+// isActiveBranch returns true for included branches that have not been moved yet
+func (g Groomba) isActiveBranch(branch string) bool {
+	return !strings.HasPrefix(branch, g.cfg.Prefix) && g.isIncludedBranch(branch)
+}
+
+// isMovedBranch returns true for branches that were already moved to Prefix
+// and are included based on their name without Prefix
+func (g Groomba) isMovedBranch(branch string) bool {
+	return strings.HasPrefix(branch, g.cfg.Prefix) && g.isIncludedBranch(strings.TrimPrefix(branch, g.cfg.Prefix))
+}
+
+// isIncludedBranch returns true if branch does not match any ExcludePatterns
+// and matches one of the IncludePatterns, if there are any
+func (g Groomba) isIncludedBranch(branch string) bool {
+	if g.cfg.matchAnyPattern(g.cfg.ExcludePatterns, branch) {
+		return false
+	}
+	return len(g.cfg.IncludePatterns) == 0 || g.cfg.matchAnyPattern(g.cfg.IncludePatterns, branch)
+}
+
+// days returns the duration of n days
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// filterBranches returns non static branches of all configured remotes with a
+// branch name accepted by match and a tip commit whose age relative to
+// referenceDate is accepted by inRange. Each remote is evaluated separately
+func (g Groomba) filterBranches(referenceDate time.Time, match func(string) bool, inRange func(time.Duration) bool) ([]*plumbing.Reference, error) {
 	branchList, err := g.repo.References() //Branches()
 	if err != nil {
 		return nil, err
@@ -76,22 +456,17 @@ func (g Groomba) FilterBranches(referenceDate time.Time) ([]*plumbing.Reference,
 
 	filteredBranches := []*plumbing.Reference{}
 	err = branchList.ForEach(func(ref *plumbing.Reference) error {
-		if ref.Type() == plumbing.HashReference && ref.Name().IsRemote() &&
-			!g.IsStaticBranch(ref.Name().String()) &&
-			!strings.HasPrefix(ref.Name().String(), "refs/remotes/origin/revert") &&
-			!strings.HasPrefix(ref.Name().String(), "refs/remotes/origin/cherry-pick") &&
-			!strings.HasPrefix(ref.Name().String(), fmt.Sprintf("refs/remotes/origin/%s", g.cfg.Prefix)) {
+		_, branch, ok := g.splitRemoteRef(ref.Name().String())
+		if ref.Type() == plumbing.HashReference && ok &&
+			!g.IsStaticBranch(ref.Name().String()) && match(branch) {
 
 			commit, err := g.repo.CommitObject(ref.Hash())
 			if err != nil {
 				log.Warnf("failed to read reference: %s, err: %s", ref, err)
+				return nil
 			}
 
-			t, err := time.ParseDuration(fmt.Sprintf("%dh", g.cfg.StaleAgeThreshold*24))
-			if err != nil {
-				log.Warnf("failed to calculate age for ref: %s, err: %s", ref, err)
-			}
-			if referenceDate.Sub(commit.Committer.When) > t {
+			if inRange(referenceDate.Sub(commit.Committer.When)) {
 				filteredBranches = append(filteredBranches, ref)
 			}
 		}
@@ -134,68 +509,207 @@ func (g Groomba) PrintBranchesGroupbyAuthor(branches []*plumbing.Reference) erro
 	return nil
 }
 
+// MoveBranch moves the branch refName on Remote to Prefix+refName
 func (g Groomba) MoveBranch(refName string) *MoveBranchError {
 	newRefName := g.cfg.Prefix + refName
 	if g.cfg.DryRun {
 		log.Infof("Would have moved branch %s to %s -- skipping since dry_run=true", refName, newRefName)
 		return nil
 	}
-	log.Infof("  copy %s to %s", refName, newRefName)
-	renameSpec := config.RefSpec(fmt.Sprintf("refs/remotes/origin/%s:refs/heads/%s", refName, newRefName))
-	err := g.repo.Push(&git.PushOptions{
-		RemoteName: "origin",
-		RefSpecs:   []config.RefSpec{renameSpec},
-		Force:      g.cfg.Clobber,
-		Auth:       g.auth.Get(),
+	if err := g.copyBranch(refName, newRefName); err != nil {
+		return &MoveBranchError{remote: g.errRemote(), branch: refName, operation: CopyBranch, err: err}
+	}
+	if err := g.deleteBranch(refName); err != nil {
+		return &MoveBranchError{remote: g.errRemote(), branch: refName, operation: DeleteBranch, err: err}
+	}
+	return nil
+}
+
+// ReviveBranch is the inverse of MoveBranch, it copies the stale branch
+// Prefix+refName on Remote back to refName and deletes the stale branch
+func (g Groomba) ReviveBranch(refName string) *ReviveBranchError {
+	staleRefName := g.cfg.Prefix + refName
+	if _, err := g.repo.Reference(g.remoteRefName(staleRefName), false); err != nil {
+		return &ReviveBranchError{remote: g.errRemote(), branch: refName, operation: CopyBranch, err: fmt.Errorf("stale branch %s: %w", staleRefName, err)}
+	}
+	if g.cfg.DryRun {
+		log.Infof("Would have revived branch %s to %s -- skipping since dry_run=true", staleRefName, refName)
+		return nil
+	}
+	if err := g.copyBranch(staleRefName, refName); err != nil {
+		return &ReviveBranchError{remote: g.errRemote(), branch: refName, operation: CopyBranch, err: err}
+	}
+	if err := g.deleteBranch(staleRefName); err != nil {
+		return &ReviveBranchError{remote: g.errRemote(), branch: refName, operation: DeleteBranch, err: err}
+	}
+	return nil
+}
+
+// DeleteStaleBranch deletes the branch refName from Remote without copying
+// it to a prefixed branch first
+func (g Groomba) DeleteStaleBranch(refName string) *MoveBranchError {
+	if g.cfg.DryRun {
+		log.Infof("Would have deleted branch %s -- skipping since dry_run=true", refName)
+		return nil
+	}
+	if err := g.deleteBranch(refName); err != nil {
+		return &MoveBranchError{remote: g.errRemote(), branch: refName, operation: DeleteBranch, err: err}
+	}
+	return nil
+}
+
+// copyBranch pushes the remote branch src to dst, overwriting dst only if
+// Clobber is enabled
+func (g Groomba) copyBranch(src, dst string) error {
+	log.Infof("  copy %s to %s", src, dst)
+	copySpec := config.RefSpec(fmt.Sprintf("%s:refs/heads/%s", g.remoteRefName(src), dst))
+	ro, err := g.remoteOptions()
+	if err != nil {
+		return err
+	}
+	err = g.repo.Push(&git.PushOptions{
+		RemoteName:      g.cfg.Remote,
+		RefSpecs:        []config.RefSpec{copySpec},
+		Force:           g.cfg.Clobber,
+		Auth:            ro.auth,
+		CABundle:        ro.caBundle,
+		InsecureSkipTLS: ro.insecureSkipTLS,
+		ProxyOptions:    ro.proxy,
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		log.Infof("  Failed to copy %s to %s with error: %s", refName, newRefName, err)
-		return &MoveBranchError{branch: refName, operation: CopyBranch, err: err}
+		log.Infof("  Failed to copy %s to %s with error: %s", src, dst, err)
+		return err
 	}
+	return nil
+}
 
+// deleteBranch deletes the branch refName from the remote
+func (g Groomba) deleteBranch(refName string) error {
 	log.Infof("  delete %s", refName)
 	deleteSpec := config.RefSpec(fmt.Sprintf(":refs/heads/%s", refName))
+	ro, err := g.remoteOptions()
+	if err != nil {
+		return err
+	}
 	err = g.repo.Push(&git.PushOptions{
-		RemoteName: "origin",
-		RefSpecs:   []config.RefSpec{deleteSpec},
-		Auth:       g.auth.Get(),
+		RemoteName:      g.cfg.Remote,
+		RefSpecs:        []config.RefSpec{deleteSpec},
+		Auth:            ro.auth,
+		CABundle:        ro.caBundle,
+		InsecureSkipTLS: ro.insecureSkipTLS,
+		ProxyOptions:    ro.proxy,
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		log.Infof("  Failed to delete %s", refName, err)
-		return &MoveBranchError{branch: refName, operation: DeleteBranch, err: err}
+		log.Infof("  Failed to delete %s with error: %s", refName, err)
+		return err
 	}
-
 	return nil
 }
 
+// MoveStaleBranches moves all branches to prefixed branches on their remote
+// using up to MaxConcurrency workers and returns a MoveStaleBranchesError with
+// all failures
 func (g Groomba) MoveStaleBranches(branches []*plumbing.Reference) error {
-	var wg sync.WaitGroup
-	errCh := make(chan *MoveBranchError) //, len(branches))
-	ch := make(chan string)
+	errList := g.processBranches(g.remoteBranches(branches), "Moving", func(g Groomba, name string) error {
+		if err := g.MoveBranch(name); err != nil {
+			return err
+		}
+		return nil
+	})
+	return newMoveStaleBranchesError(errList)
+}
+
+// DeleteStaleBranches deletes all branches from their remote using up to
+// MaxConcurrency workers and returns a MoveStaleBranchesError with all failures
+func (g Groomba) DeleteStaleBranches(branches []*plumbing.Reference) error {
+	errList := g.processBranches(g.remoteBranches(branches), "Deleting", func(g Groomba, name string) error {
+		if err := g.DeleteStaleBranch(name); err != nil {
+			return err
+		}
+		return nil
+	})
+	return newMoveStaleBranchesError(errList)
+}
+
+// ReviveBranches revives all named branches on every configured remote using
+// up to MaxConcurrency workers and returns a ReviveBranchesError with all
+// failures
+func (g Groomba) ReviveBranches(names []string) error {
+	branches := []remoteBranch{}
+	for _, remote := range g.cfg.remotes() {
+		for _, name := range names {
+			branches = append(branches, remoteBranch{remote: remote, name: name})
+		}
+	}
+	return g.reviveBranches(branches)
+}
 
-	branchNames := []string{}
+// ReviveStaleBranches revives all stale branches on their remote using up to
+// MaxConcurrency workers and returns a ReviveBranchesError with all failures
+func (g Groomba) ReviveStaleBranches(branches []*plumbing.Reference) error {
+	rb := g.remoteBranches(branches)
+	for i := range rb {
+		rb[i].name = strings.TrimPrefix(rb[i].name, g.cfg.Prefix)
+	}
+	return g.reviveBranches(rb)
+}
+
+func (g Groomba) reviveBranches(branches []remoteBranch) error {
+	errList := g.processBranches(branches, "Reviving", func(g Groomba, name string) error {
+		if err := g.ReviveBranch(name); err != nil {
+			return err
+		}
+		return nil
+	})
+	return newReviveBranchesError(errList)
+}
+
+// remoteBranch identifies a branch on one of the configured remotes
+type remoteBranch struct {
+	remote string
+	name   string
+}
+
+// remoteBranches returns the remote and branch name of remote tracking references
+func (g Groomba) remoteBranches(branches []*plumbing.Reference) []remoteBranch {
+	rb := []remoteBranch{}
 	for _, ref := range branches {
-		wg.Add(1)
 		log.Debugf("ref: %s", ref.Name())
-		branchNames = append(branchNames, ref.Name().Short()[7:])
-		log.Debugf("branchNames: %s", branchNames)
+		remote, name, ok := g.splitRemoteRef(ref.Name().String())
+		if !ok {
+			log.Warnf("skipping ref: %s, it does not belong to any configured remote", ref.Name())
+			continue
+		}
+		rb = append(rb, remoteBranch{remote: remote, name: name})
 	}
-	log.Debugf("%s", branchNames)
-	go func(branchNames []string) {
-		// send branches to move to ch
-		for _, ref := range branchNames {
-			log.Debugf("sending ref: %s", ref)
-			ch <- ref
+	log.Debugf("remoteBranches: %v", rb)
+	return rb
+}
+
+// processBranches runs op for each branch with a Groomba operating on the
+// branch's remote concurrently using up to MaxConcurrency workers and returns
+// all errors
+func (g Groomba) processBranches(branches []remoteBranch, action string, op func(Groomba, string) error) []error {
+	var wg sync.WaitGroup
+	errCh := make(chan error) //, len(branches))
+	ch := make(chan remoteBranch)
+
+	wg.Add(len(branches))
+	go func(branches []remoteBranch) {
+		// send branches to process to ch
+		for _, b := range branches {
+			log.Debugf("sending branch: %s on remote: %s", b.name, b.remote)
+			ch <- b
 		}
 		close(ch)
-	}(branchNames)
+	}(branches)
 	for i := uint8(0); i < g.cfg.MaxConcurrency; i++ {
-		// Create workers to move branches
-		go func(ch <-chan string) {
-			for refName := range ch {
-				log.Infof("Moving branch %s", refName)
-				err := g.MoveBranch(refName)
-				log.Debugf("branch: %s, returned error: %s", refName, err)
+		// Create workers to process branches
+		go func(ch <-chan remoteBranch) {
+			for b := range ch {
+				log.Infof("%s branch %s on remote %s", action, b.name, b.remote)
+				err := op(g.onRemote(b.remote), b.name)
+				log.Debugf("branch: %s, remote: %s, returned error: %s", b.name, b.remote, err)
 				if err != nil {
 					errCh <- err
 				}
@@ -205,13 +719,13 @@ func (g Groomba) MoveStaleBranches(branches []*plumbing.Reference) error {
 	}
 
 	// channel to get aggregated list of errors
-	errListCh := make(chan []MoveBranchError)
+	errListCh := make(chan []error)
 	defer close(errListCh)
-	go func(errorListCh chan<- []MoveBranchError) {
-		errList := []MoveBranchError{}
+	go func(errorListCh chan<- []error) {
+		errList := []error{}
 		for e := range errCh {
 			log.Debugf("%s", e)
-			errList = append(errList, *e)
+			errList = append(errList, e)
 		}
 		errListCh <- errList
 	}(errListCh)
@@ -219,10 +733,5 @@ func (g Groomba) MoveStaleBranches(branches []*plumbing.Reference) error {
 	wg.Wait()
 	close(errCh)
 
-	errList := <-errListCh
-	if len(errList) != 0 {
-		return &MoveStaleBranchesError{errList: errList}
-	}
-
-	return nil
+	return <-errListCh
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -25,11 +26,17 @@ type MockAuthenticator struct {
 	auth transport.AuthMethod
 }
 
-func (ma *MockAuthenticator) Get() transport.AuthMethod {
-	return ma.auth
+func (ma *MockAuthenticator) Get(url string) (transport.AuthMethod, error) {
+	return ma.auth, nil
 }
 
 func InitTest() {
+	InitTestWithRemote("origin")
+}
+
+// InitTestWithRemote sets up the test repos with the cloned repo using remote
+// as the name of the remote for the source repo
+func InitTestWithRemote(remote string) {
 	// cleanup dirs from previous tests
 	os.RemoveAll("testdata/src")
 	os.RemoveAll("testdata/dst")
@@ -83,7 +90,7 @@ func InitTest() {
 	}
 
 	// create cloned repo
-	err = exec.Command("git", "clone", "testdata/src", "testdata/dst").Run()
+	err = exec.Command("git", "clone", "--origin", remote, "testdata/src", "testdata/dst").Run()
 	CheckTestInitError(err)
 }
 
@@ -91,7 +98,7 @@ func ExampleGroomba_PrintBranchesGroupbyAuthor() {
 	InitTest()
 
 	cfg, _ := GetConfig(".")
-	repo, _ := git.PlainOpen("testdata/dst")
+	repo, _ := PlainOpen("testdata/dst")
 	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
 
 	fb, _ := g.FilterBranches(time.Now())
@@ -109,7 +116,7 @@ func TestGroomba(t *testing.T) {
 
 	os.Setenv("GROOMBA_PREFIX", "stale/")
 	cfg, _ := GetConfig(".")
-	repo, _ := git.PlainOpen("testdata/dst")
+	repo, _ := PlainOpen("testdata/dst")
 	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
 	t.Run("main branch should be static", func(t *testing.T) {
 		a := assert.New(t)
@@ -182,7 +189,7 @@ func TestGroombaDryRun(t *testing.T) {
 
 	os.Setenv("GROOMBA_DRY_RUN", "true")
 	cfg, _ := GetConfig(".")
-	repo, _ := git.PlainOpen("testdata/dst")
+	repo, _ := PlainOpen("testdata/dst")
 	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
 	t.Run("main branch should be static", func(t *testing.T) {
 		a := assert.New(t)
@@ -256,7 +263,7 @@ func TestGroombaPrefix(t *testing.T) {
 	os.Setenv("GROOMBA_PREFIX", "zzz/")
 	os.Setenv("GROOMBA_DRY_RUN", "false")
 	cfg, _ := GetConfig(".")
-	repo, _ := git.PlainOpen("testdata/dst")
+	repo, _ := PlainOpen("testdata/dst")
 	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
 	t.Run("main branch should be static", func(t *testing.T) {
 		a := assert.New(t)
@@ -391,7 +398,7 @@ func TestGroombaClobber(t *testing.T) {
 	os.Setenv("GROOMBA_PREFIX", "stale/")
 	os.Setenv("GROOMBA_CLOBBER", "false")
 	cfg, _ := GetConfig(".")
-	repo, _ := git.PlainOpen("testdata/dst")
+	repo, _ := PlainOpen("testdata/dst")
 	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
 
 	today := time.Now()
@@ -436,3 +443,643 @@ func TestGroombaClobber(t *testing.T) {
 		a.Nil(err)
 	})
 }
+
+func TestGroombaDelete(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_DELETE_AGE_THRESHOLD", "15")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	today := time.Now()
+	fb, _ := g.FilterBranches(today)
+	db, _ := g.FilterDeleteBranches(today)
+	t.Run("Branches older than delete threshold should not be moved", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(0, len(fb))
+	})
+
+	t.Run("Branches older than delete threshold should be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(2, len(db))
+		actual := db[0].Name().Short()
+		a.Equal("origin/IsStale", actual)
+	})
+
+	dryCfg := *cfg
+	dryCfg.DryRun = true
+	gd := Groomba{cfg: &dryCfg, repo: repo, auth: &MockAuthenticator{}}
+	err := gd.DeleteStaleBranches(db)
+	assert.Nil(t, err)
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	t.Run("stale branch should not be deleted from origin in dry_run mode", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsStale", false)
+		a.Nil(err)
+	})
+
+	err = g.DeleteStaleBranches(db)
+	assert.Nil(t, err)
+
+	t.Run("stale branch should be deleted from origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsStale", false)
+		a.NotNil(err)
+		if err != nil {
+			a.Equal("reference not found", err.Error())
+		}
+	})
+
+	t.Run("deleted branch should not be renamed at origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale", false)
+		a.NotNil(err)
+	})
+
+	t.Run("origin should have exactly 5 branches", func(t *testing.T) {
+		a := assert.New(t)
+		count := 0
+		b, _ := upstream.Branches()
+		err := b.ForEach(func(ref *plumbing.Reference) error {
+			count++
+			return nil
+		})
+		a.Nil(err)
+		a.Equal(5, count)
+	})
+}
+
+func TestGroombaPurge(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_PURGE_AGE_THRESHOLD", "30")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	today := time.Now()
+	pb, _ := g.FilterPurgeBranches(today)
+	t.Run("Stale branches newer than purge threshold should not be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(0, len(pb))
+	})
+
+	g.cfg.PurgeAgeThreshold = 15
+	pb, _ = g.FilterPurgeBranches(today)
+	t.Run("Only stale branches older than purge threshold should be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(pb))
+		actual := pb[0].Name().Short()
+		a.Equal("origin/stale/IsStale1", actual)
+	})
+
+	err := g.DeleteStaleBranches(pb)
+	assert.Nil(t, err)
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	t.Run("purged branch should be deleted from origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale1", false)
+		a.NotNil(err)
+		if err != nil {
+			a.Equal("reference not found", err.Error())
+		}
+	})
+
+	t.Run("stale branch without prefix should not be purged", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsStale", false)
+		a.Nil(err)
+	})
+}
+
+func TestGroombaGroom(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_CLOBBER", "false")
+	t.Setenv("GROOMBA_PURGE_AGE_THRESHOLD", "15")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	report, err := g.Groom(time.Now())
+	assert.Nil(t, err)
+	t.Run("branches should be filtered before any of them is moved", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal([]string{"origin/IsStale", "origin/IsStale2"}, report.Moved)
+		a.Equal([]string{"origin/stale/IsStale1"}, report.Purged)
+	})
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	t.Run("branches moved in the same run should not be purged", func(t *testing.T) {
+		a := assert.New(t)
+		for _, name := range []string{"stale/IsStale", "stale/IsStale2"} {
+			_, err := upstream.Reference(plumbing.NewBranchReferenceName(name), false)
+			a.Nil(err, name)
+		}
+		_, err := upstream.Reference("refs/heads/stale/IsStale1", false)
+		a.NotNil(err)
+	})
+}
+
+func TestGroombaRevive(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "true")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+	upstream, _ := git.PlainOpen("testdata/src")
+
+	t.Run("ReviveBranch should not revive branch in dry_run mode", func(t *testing.T) {
+		a := assert.New(t)
+		err := g.ReviveBranch("IsStale1")
+		a.Nil(err)
+		_, rerr := upstream.Reference("refs/heads/IsStale1", false)
+		a.NotNil(rerr)
+	})
+
+	g.cfg.DryRun = false
+	t.Run("ReviveBranch should fail for branch that is not stale", func(t *testing.T) {
+		a := assert.New(t)
+		err := g.ReviveBranch("IsFresh")
+		expectedErrMsg := "branch: IsFresh failed to revive on operation copy with error: stale branch stale/IsFresh: reference not found"
+		a.NotNil(err)
+		if err != nil {
+			a.Equal(expectedErrMsg, err.Error())
+		}
+	})
+
+	err := g.ReviveBranches([]string{"IsStale1"})
+	assert.Nil(t, err)
+
+	t.Run("revived branch should be restored at origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsStale1", false)
+		a.Nil(err)
+	})
+
+	t.Run("stale branch should be removed from origin after revive", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale1", false)
+		a.NotNil(err)
+		if err != nil {
+			a.Equal("reference not found", err.Error())
+		}
+	})
+
+	t.Run("ReviveBranches should return all failures", func(t *testing.T) {
+		a := assert.New(t)
+		err := g.ReviveBranches([]string{"Missing1", "Missing2"})
+		expectedErrMsg := "branch: Missing1 failed to revive on operation copy with error: stale branch stale/Missing1: reference not found\n" +
+			"branch: Missing2 failed to revive on operation copy with error: stale branch stale/Missing2: reference not found"
+		a.NotNil(err)
+		if err != nil {
+			a.Equal(expectedErrMsg, err.Error())
+		}
+	})
+}
+
+func TestGroombaAutoRevive(t *testing.T) {
+	InitTest()
+
+	// simulate a new commit pushed to an already stale branch
+	err := exec.Command("git", "-C", "testdata/src", "branch", "stale/IsRevived", "IsFresh").Run()
+	CheckTestInitError(err)
+	err = exec.Command("git", "-C", "testdata/dst", "fetch", "origin").Run()
+	CheckTestInitError(err)
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	today := time.Now()
+	rb, _ := g.FilterReviveBranches(today)
+	t.Run("No branches should be revived unless auto_revive is enabled", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(0, len(rb))
+	})
+
+	g.cfg.AutoRevive = true
+	rb, _ = g.FilterReviveBranches(today)
+	t.Run("Only stale branches with fresh commits should be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(rb))
+		actual := rb[0].Name().Short()
+		a.Equal("origin/stale/IsRevived", actual)
+	})
+
+	t.Run("Branches to revive should not be purged", func(t *testing.T) {
+		a := assert.New(t)
+		g.cfg.PurgeAgeThreshold = 3
+		defer func() { g.cfg.PurgeAgeThreshold = 0 }()
+		pb, err := g.FilterPurgeBranches(today)
+		a.Nil(err)
+		names := []string{}
+		for _, ref := range pb {
+			names = append(names, ref.Name().Short())
+		}
+		a.NotContains(names, "origin/stale/IsRevived")
+		a.Contains(names, "origin/stale/IsStale1")
+	})
+
+	err = g.ReviveStaleBranches(rb)
+	assert.Nil(t, err)
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	t.Run("fresh stale branch should be revived at origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsRevived", false)
+		a.Nil(err)
+		_, err = upstream.Reference("refs/heads/stale/IsRevived", false)
+		a.NotNil(err)
+	})
+
+	t.Run("old stale branch should not be revived at origin", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale1", false)
+		a.Nil(err)
+	})
+}
+
+func TestGroombaRemote(t *testing.T) {
+	InitTestWithRemote("upstream")
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_REMOTE", "upstream")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+	t.Run("main branch should be static for non origin remote", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(true, g.IsStaticBranch("refs/remotes/upstream/master"))
+		a.Equal(false, g.IsStaticBranch("refs/remotes/origin/master"))
+	})
+
+	err := g.Fetch()
+	assert.Nil(t, err)
+
+	today := time.Now()
+	fb, _ := g.FilterBranches(today)
+	t.Run("Only stale branches should be detected for non origin remote", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(2, len(fb))
+		actual := fb[0].Name().Short()
+		a.Equal("upstream/IsStale", actual)
+	})
+
+	err = g.MoveStaleBranches(fb)
+	assert.Nil(t, err)
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	t.Run("stale branch should be removed from non origin remote", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/IsStale", false)
+		a.NotNil(err)
+		if err != nil {
+			a.Equal("reference not found", err.Error())
+		}
+	})
+
+	t.Run("stale branch should be renamed at non origin remote", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale", false)
+		a.Nil(err)
+		_, err = upstream.Reference("refs/heads/stale/IsStale2", false)
+		a.Nil(err)
+	})
+
+	err = g.Fetch()
+	assert.Nil(t, err)
+	t.Run("ReviveBranch should work for non origin remote", func(t *testing.T) {
+		a := assert.New(t)
+		err := g.ReviveBranch("IsStale2")
+		a.Nil(err)
+		_, rerr := upstream.Reference("refs/heads/IsStale2", false)
+		a.Nil(rerr)
+	})
+}
+
+func TestGroombaMultipleRemotes(t *testing.T) {
+	InitTest()
+
+	// create a mirror of the source repo and add it as a second remote
+	os.RemoveAll("testdata/mirror")
+	defer os.RemoveAll("testdata/mirror")
+	err := exec.Command("git", "clone", "--bare", "testdata/src", "testdata/mirror").Run()
+	CheckTestInitError(err)
+	mirrorPath, _ := filepath.Abs("testdata/mirror")
+	err = exec.Command("git", "-C", "testdata/dst", "remote", "add", "mirror", mirrorPath).Run()
+	CheckTestInitError(err)
+	// stale/IsStale on the mirror diverges so moving IsStale fails only on the mirror
+	err = exec.Command("git", "-C", "testdata/mirror", "branch", "stale/IsStale", "IsFresh").Run()
+	CheckTestInitError(err)
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_CLOBBER", "false")
+	t.Setenv("GROOMBA_REMOTES", "origin,mirror")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	err = g.Fetch()
+	assert.Nil(t, err)
+
+	t.Run("main branch should be static on every remote", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/master"))
+		a.Equal(true, g.IsStaticBranch("refs/remotes/mirror/master"))
+	})
+
+	today := time.Now()
+	fb, _ := g.FilterBranches(today)
+	t.Run("Stale branches should be detected on every remote", func(t *testing.T) {
+		a := assert.New(t)
+		names := []string{}
+		for _, ref := range fb {
+			names = append(names, ref.Name().Short())
+		}
+		sort.Strings(names)
+		a.Equal([]string{"mirror/IsStale", "mirror/IsStale2", "origin/IsStale", "origin/IsStale2"}, names)
+	})
+
+	t.Run("MoveStaleBranches should report failures per remote", func(t *testing.T) {
+		a := assert.New(t)
+		err := g.MoveStaleBranches(fb)
+		expectedErrMsg := "remote: mirror branch: IsStale failed on operation copy with error: non-fast-forward update: refs/heads/stale/IsStale"
+		a.NotNil(err)
+		if err != nil {
+			a.Equal(expectedErrMsg, err.Error())
+		}
+	})
+
+	upstream, _ := git.PlainOpen("testdata/src")
+	mirror, _ := git.PlainOpen("testdata/mirror")
+	t.Run("stale branches should be renamed on every remote", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := upstream.Reference("refs/heads/stale/IsStale", false)
+		a.Nil(err)
+		_, err = upstream.Reference("refs/heads/stale/IsStale2", false)
+		a.Nil(err)
+		_, err = mirror.Reference("refs/heads/stale/IsStale2", false)
+		a.Nil(err)
+		_, err = mirror.Reference("refs/heads/IsStale2", false)
+		a.NotNil(err)
+	})
+
+	t.Run("branch that failed to move should be kept on the failing remote", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := mirror.Reference("refs/heads/IsStale", false)
+		a.Nil(err)
+		_, err = upstream.Reference("refs/heads/IsStale", false)
+		a.NotNil(err)
+	})
+}
+
+func TestGroombaStaticBranchPatterns(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_STATIC_BRANCHES", "master,IsStale?,/Is(Fresh)+/")
+	cfg, err := GetConfig(".")
+	assert.Nil(t, err)
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+	t.Run("branches matching glob and regex patterns should be static", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/master"))
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/IsStale2"))
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/IsFresh"))
+		a.Equal(false, g.IsStaticBranch("refs/remotes/origin/IsStale"))
+		a.Equal(false, g.IsStaticBranch("refs/remotes/origin/IsFresh2"))
+	})
+
+	fb, _ := g.FilterBranches(time.Now())
+	t.Run("branches matching static patterns should not be detected as stale", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(fb))
+		actual := fb[0].Name().Short()
+		a.Equal("origin/IsStale", actual)
+	})
+}
+
+func TestGroombaIncludeExcludePatterns(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	cfg, err := GetConfig(".")
+	assert.Nil(t, err)
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	t.Run("revert and cherry-pick branches should be excluded by default", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(false, g.isActiveBranch("revert-abc"))
+		a.Equal(false, g.isActiveBranch("revert/abc"))
+		a.Equal(false, g.isActiveBranch("cherry-pick-abc"))
+		a.Equal(true, g.isActiveBranch("feature/abc"))
+		a.Equal(false, g.isMovedBranch("stale/revert-abc"))
+	})
+
+	g.cfg.ExcludePatterns = []string{"IsStale2"}
+	fb, _ := g.FilterBranches(time.Now())
+	t.Run("branches matching exclude patterns should not be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(fb))
+		actual := fb[0].Name().Short()
+		a.Equal("origin/IsStale", actual)
+	})
+
+	g.cfg.ExcludePatterns = []string{}
+	g.cfg.IncludePatterns = []string{"/.*2/"}
+	fb, _ = g.FilterBranches(time.Now())
+	t.Run("only branches matching include patterns should be detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(fb))
+		actual := fb[0].Name().Short()
+		a.Equal("origin/IsStale2", actual)
+	})
+
+	g.cfg.IncludePatterns = []string{"IsStale*"}
+	g.cfg.PurgeAgeThreshold = 15
+	pb, _ := g.FilterPurgeBranches(time.Now())
+	t.Run("include patterns should match stale branches without prefix", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(pb))
+		actual := pb[0].Name().Short()
+		a.Equal("origin/stale/IsStale1", actual)
+	})
+}
+
+func TestGroombaProtectDefaultBranch(t *testing.T) {
+	InitTest()
+
+	// change the default branch of the source repo and forget the remote HEAD
+	// in the cloned repo so it has to be detected from the remote
+	err := exec.Command("git", "-C", "testdata/src", "checkout", "IsStale2").Run()
+	CheckTestInitError(err)
+	err = exec.Command("git", "-C", "testdata/dst", "remote", "set-head", "origin", "--delete").Run()
+	CheckTestInitError(err)
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_STATIC_BRANCHES", "master")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	t.Run("default branch should not be static before it is detected", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(false, g.IsStaticBranch("refs/remotes/origin/IsStale2"))
+	})
+
+	err = g.Fetch()
+	assert.Nil(t, err)
+
+	t.Run("detected default branch should be static", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/IsStale2"))
+		a.Equal(true, g.IsStaticBranch("refs/remotes/origin/master"))
+	})
+
+	fb, _ := g.FilterBranches(time.Now())
+	t.Run("default branch should not be detected as stale", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(1, len(fb))
+		actual := fb[0].Name().Short()
+		a.Equal("origin/IsStale", actual)
+	})
+
+	g.cfg.ProtectDefaultBranch = false
+	fb, _ = g.FilterBranches(time.Now())
+	t.Run("default branch should be detected as stale when protection is disabled", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(2, len(fb))
+		a.Equal(false, g.IsStaticBranch("refs/remotes/origin/IsStale2"))
+	})
+}
+
+// MockCredentialReporter is a MockAuthenticator that counts how often the
+// credentials of each url were approved and rejected
+type MockCredentialReporter struct {
+	MockAuthenticator
+	approved map[string]int
+	rejected map[string]int
+}
+
+func (mr *MockCredentialReporter) Approve(url string) error {
+	mr.approved[url]++
+	return nil
+}
+
+func (mr *MockCredentialReporter) Reject(url string) error {
+	mr.rejected[url]++
+	return nil
+}
+
+func TestGroombaReportCredentials(t *testing.T) {
+	InitTest()
+
+	t.Setenv("GROOMBA_REMOTE", "origin")
+	cfg, _ := GetConfig(".")
+	repo, _ := PlainOpen("testdata/dst")
+	mr := &MockCredentialReporter{approved: map[string]int{}, rejected: map[string]int{}}
+	g := Groomba{cfg: cfg, repo: repo, auth: mr}
+	// git clone stores the absolute path of local remotes
+	src, _ := filepath.Abs("testdata/src")
+
+	t.Run("credentials should be approved after a successful fetch", func(t *testing.T) {
+		a := assert.New(t)
+		a.Nil(g.Fetch())
+		a.Equal(map[string]int{src: 1}, mr.approved)
+		a.Equal(map[string]int{}, mr.rejected)
+	})
+
+	t.Run("credentials should be rejected on auth failures only", func(t *testing.T) {
+		a := assert.New(t)
+		delete(mr.approved, src)
+		url := "https://git.example.com/avbm/groomba.git"
+		g.reportCredentials(url, fmt.Errorf("remote origin: %w", transport.ErrAuthenticationRequired))
+		g.reportCredentials(url, transport.ErrAuthorizationFailed)
+		g.reportCredentials(url, transport.ErrRepositoryNotFound)
+		a.Equal(map[string]int{}, mr.approved)
+		a.Equal(map[string]int{url: 2}, mr.rejected)
+	})
+}
+
+func TestGroombaMemoryRepository(t *testing.T) {
+	InitTest()
+
+	// commit a config file to the default branch of the source repo
+	err := os.WriteFile("testdata/src/.groomba.yaml", []byte("delete_age_threshold: 30\npurge_age_threshold: 60\n"), 0644)
+	assert.Nil(t, err)
+	for _, args := range [][]string{{"add", ".groomba.yaml"}, {"commit", "-m", "Add_config"}} {
+		err = exec.Command("git", append([]string{"-C", "testdata/src"}, args...)...).Run()
+		assert.Nil(t, err)
+	}
+
+	// go-git can only push to local remotes without a worktree, like the bare
+	// repos on git servers, from a shallow repository
+	os.RemoveAll("testdata/mirror")
+	defer os.RemoveAll("testdata/mirror")
+	err = exec.Command("git", "clone", "--bare", "testdata/src", "testdata/mirror").Run()
+	assert.Nil(t, err)
+
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_DRY_RUN", "false")
+	t.Setenv("GROOMBA_REMOTE", "origin")
+	cfg, err := GetConfigFromTree(nil)
+	assert.Nil(t, err)
+	mirrorPath, _ := filepath.Abs("testdata/mirror")
+	repo, err := NewMemoryRepository("origin", mirrorPath)
+	assert.Nil(t, err)
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	err = g.Fetch()
+	assert.Nil(t, err)
+	t.Run("branches should be fetched into the in-memory repository", func(t *testing.T) {
+		fb, err := g.FilterBranches(time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(fb))
+	})
+
+	t.Run("config should be read from the default branch", func(t *testing.T) {
+		a := assert.New(t)
+		tree, err := g.DefaultBranchTree()
+		a.Nil(err)
+		cfg, err := GetConfigFromTree(tree)
+		a.Nil(err)
+		a.Equal(30, cfg.DeleteAgeThreshold)
+		a.Equal(60, cfg.PurgeAgeThreshold)
+		a.Equal("stale/", cfg.Prefix, "environment variables should override the config file")
+	})
+
+	t.Run("config without a tree should only use defaults and environment", func(t *testing.T) {
+		cfg, err := GetConfigFromTree(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, cfg.DeleteAgeThreshold)
+	})
+
+	t.Run("stale branches should be moved on the remote", func(t *testing.T) {
+		a := assert.New(t)
+		fb, _ := g.FilterBranches(time.Now())
+		a.Nil(g.MoveStaleBranches(fb))
+		mirror, _ := git.PlainOpen("testdata/mirror")
+		_, err := mirror.Reference("refs/heads/stale/IsStale", false)
+		a.Nil(err)
+		_, err = mirror.Reference("refs/heads/IsStale", false)
+		a.NotNil(err)
+	})
+}
@@ -0,0 +1,258 @@
+package groomba
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the repositories groomed by RunAll
+type Manifest struct {
+	// MaxConcurrency is the maximum number of repositories groomed at the same time
+	MaxConcurrency uint8 `yaml:"max_concurrency"`
+	// Repositories to groom
+	Repositories []ManifestRepository `yaml:"repositories"`
+}
+
+// ManifestRepository is a repository of a Manifest, either a URL groomed in
+// memory or the Path of a local checkout. Config overrides the settings of
+// the config file of the repository
+type ManifestRepository struct {
+	Name   string                 `yaml:"name"`
+	URL    string                 `yaml:"url"`
+	Path   string                 `yaml:"path"`
+	Config map[string]interface{} `yaml:"config"`
+}
+
+// defaultManifestConcurrency is the number of repositories groomed at the
+// same time if the manifest does not set max_concurrency
+const defaultManifestConcurrency = 4
+
+// LoadManifest reads and validates the YAML manifest file. Relative paths of
+// repositories are relative to the directory of the manifest
+func LoadManifest(file string) (*Manifest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("loadManifest: %s", err)
+	}
+	m := &Manifest{MaxConcurrency: defaultManifestConcurrency}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("loadManifest: failed to parse %s: %s", file, err)
+	}
+	if m.MaxConcurrency == 0 {
+		m.MaxConcurrency = 1
+	}
+	if len(m.Repositories) == 0 {
+		return nil, fmt.Errorf("loadManifest: %s lists no repositories", file)
+	}
+
+	names := map[string]bool{}
+	for i := range m.Repositories {
+		r := &m.Repositories[i]
+		if (r.URL == "") == (r.Path == "") {
+			return nil, fmt.Errorf("loadManifest: repository %d must set exactly one of url or path", i+1)
+		}
+		if r.Path != "" && !filepath.IsAbs(r.Path) {
+			r.Path = filepath.Join(filepath.Dir(file), r.Path)
+		}
+		if r.Name == "" {
+			r.Name = r.URL
+			if r.Path != "" {
+				r.Name = r.Path
+			}
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("loadManifest: repository %s is listed more than once", r.Name)
+		}
+		names[r.Name] = true
+		for key := range r.Config {
+			if !isConfigKey(key) {
+				return nil, fmt.Errorf("loadManifest: repository %s: unknown config key %s", r.Name, key)
+			}
+		}
+	}
+	return m, nil
+}
+
+// GroomReport lists the branches Groom acted on successfully by their short
+// remote tracking reference name, eg origin/foo, in sorted order
+type GroomReport struct {
+	Moved   []string `yaml:"moved,omitempty"`
+	Deleted []string `yaml:"deleted,omitempty"`
+	Purged  []string `yaml:"purged,omitempty"`
+	Revived []string `yaml:"revived,omitempty"`
+}
+
+// Groom moves, deletes, purges and revives the branches found by the Filter
+// functions like groomba move does. All branches are filtered before any step
+// runs since pushing updates the remote tracking references, otherwise a
+// branch moved by the first step would be purged by a later one. Every step
+// runs even if some branches fail, the errors of all steps are joined
+func (g Groomba) Groom(referenceDate time.Time) (GroomReport, error) {
+	var report GroomReport
+	var errs []error
+	steps := []struct {
+		filter   func(time.Time) ([]*plumbing.Reference, error)
+		run      func([]*plumbing.Reference) error
+		names    *[]string
+		branches []*plumbing.Reference
+		err      error
+	}{
+		{filter: g.FilterBranches, run: g.MoveStaleBranches, names: &report.Moved},
+		{filter: g.FilterDeleteBranches, run: g.DeleteStaleBranches, names: &report.Deleted},
+		{filter: g.FilterPurgeBranches, run: g.DeleteStaleBranches, names: &report.Purged},
+		{filter: g.FilterReviveBranches, run: g.ReviveStaleBranches, names: &report.Revived},
+	}
+	for i := range steps {
+		steps[i].branches, steps[i].err = steps[i].filter(referenceDate)
+	}
+	for _, step := range steps {
+		if step.err != nil {
+			errs = append(errs, step.err)
+			continue
+		}
+		err := step.run(step.branches)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		failed := g.failedBranches(err)
+		for _, b := range step.branches {
+			if name := b.Name().Short(); !failed[name] {
+				*step.names = append(*step.names, name)
+			}
+		}
+		sort.Strings(*step.names)
+	}
+	return report, errors.Join(errs...)
+}
+
+// failedBranches returns the short remote tracking reference names of the
+// branches listed in the MoveStaleBranchesError or ReviveBranchesError err
+func (g Groomba) failedBranches(err error) map[string]bool {
+	failed := map[string]bool{}
+	name := func(remote, branch string) string {
+		if remote == "" {
+			remote = g.cfg.Remote
+		}
+		return plumbing.NewRemoteReferenceName(remote, branch).Short()
+	}
+	var moveErr *MoveStaleBranchesError
+	if errors.As(err, &moveErr) {
+		for _, e := range moveErr.errList {
+			failed[name(e.remote, e.branch)] = true
+		}
+	}
+	var reviveErr *ReviveBranchesError
+	if errors.As(err, &reviveErr) {
+		// revived branches are reported by the name they had with Prefix
+		for _, e := range reviveErr.errList {
+			failed[name(e.remote, g.cfg.Prefix+e.branch)] = true
+		}
+	}
+	return failed
+}
+
+// RepositoryReport is the result of grooming one repository of a manifest
+type RepositoryReport struct {
+	Name        string `yaml:"name"`
+	DryRun      bool   `yaml:"dry_run,omitempty"`
+	GroomReport `yaml:",inline"`
+	Error       string `yaml:"error,omitempty"`
+}
+
+// RunAllReport is the aggregated report of RunAll with the repositories in
+// manifest order
+type RunAllReport struct {
+	Repositories []RepositoryReport `yaml:"repositories"`
+}
+
+// Print prints the report as YAML
+func (r RunAllReport) Print() error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// RunAll grooms every repository of the manifest with the default config
+// loader, see ConfigLoader.RunAll
+func (m *Manifest) RunAll(referenceDate time.Time) (RunAllReport, error) {
+	return defaultLoader.RunAll(m, referenceDate)
+}
+
+// RunAll grooms every repository of the manifest, up to MaxConcurrency at
+// the same time, loading their configs with l. It returns the report of all
+// repositories and a RunAllError with the errors of the repositories that
+// failed
+func (l *ConfigLoader) RunAll(m *Manifest, referenceDate time.Time) (RunAllReport, error) {
+	report := RunAllReport{Repositories: make([]RepositoryReport, len(m.Repositories))}
+	errList := make([]error, len(m.Repositories))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < int(m.MaxConcurrency); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				report.Repositories[i], errList[i] = m.Repositories[i].groom(l, referenceDate)
+			}
+		}()
+	}
+	for i := range m.Repositories {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return report, newRunAllError(errList)
+}
+
+// groom opens the repository with the config loaded by l and grooms it
+func (r ManifestRepository) groom(l *ConfigLoader, referenceDate time.Time) (RepositoryReport, error) {
+	log.Infof("Grooming repository %s", r.Name)
+	report := RepositoryReport{Name: r.Name}
+
+	var g Groomba
+	var err error
+	if r.URL != "" {
+		g, err = l.openURL(r.URL, r.Config)
+	} else {
+		g, err = l.openPath(r.Path, r.Config)
+	}
+	if err == nil {
+		report.DryRun = g.cfg.DryRun
+		report.GroomReport, err = g.Groom(referenceDate)
+	}
+	if err != nil {
+		report.Error = err.Error()
+		return report, &RepositoryError{repository: r.Name, err: err}
+	}
+	return report, nil
+}
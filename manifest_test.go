@@ -0,0 +1,152 @@
+package groomba
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "manifest.yaml")
+	err := os.WriteFile(file, []byte(content), 0644)
+	assert.Nil(t, err)
+	return file
+}
+
+func TestLoadManifest(t *testing.T) {
+	t.Run("defaults and relative paths", func(t *testing.T) {
+		a := assert.New(t)
+		file := writeManifest(t, `
+repositories:
+  - url: https://example.com/foo.git
+    config:
+      dry_run: true
+  - name: bar
+    path: repos/bar
+`)
+		m, err := LoadManifest(file)
+		a.Nil(err)
+		a.Equal(uint8(defaultManifestConcurrency), m.MaxConcurrency)
+		a.Equal(2, len(m.Repositories))
+		a.Equal("https://example.com/foo.git", m.Repositories[0].Name)
+		a.Equal(map[string]interface{}{"dry_run": true}, m.Repositories[0].Config)
+		a.Equal("bar", m.Repositories[1].Name)
+		a.Equal(filepath.Join(filepath.Dir(file), "repos/bar"), m.Repositories[1].Path)
+	})
+
+	t.Run("zero max_concurrency grooms one repository at a time", func(t *testing.T) {
+		m, err := LoadManifest(writeManifest(t, "max_concurrency: 0\nrepositories:\n  - path: /foo\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(1), m.MaxConcurrency)
+	})
+
+	t.Run("invalid manifests", func(t *testing.T) {
+		for content, expectedErrMsg := range map[string]string{
+			"repositories: []\n":             "lists no repositories",
+			"repositories:\n  - name: foo\n": "loadManifest: repository 1 must set exactly one of url or path",
+			"repositories:\n  - url: https://example.com/foo.git\n    path: /foo\n": "loadManifest: repository 1 must set exactly one of url or path",
+			"repositories:\n  - path: /foo\n  - path: /foo\n":                       "loadManifest: repository /foo is listed more than once",
+			"repositories:\n  - path: /foo\n    config:\n      colour: blue\n":      "loadManifest: repository /foo: unknown config key colour",
+		} {
+			_, err := LoadManifest(writeManifest(t, content))
+			if assert.NotNil(t, err, content) {
+				assert.Contains(t, err.Error(), expectedErrMsg)
+			}
+		}
+		_, err := LoadManifest(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestManifestRunAll(t *testing.T) {
+	InitTest()
+
+	// go-git can only push to local remotes without a worktree from a shallow
+	// repository, see TestGroombaMemoryRepository
+	for _, dir := range []string{"testdata/mirror", "testdata/mirror2"} {
+		os.RemoveAll(dir)
+		defer os.RemoveAll(dir)
+		err := exec.Command("git", "clone", "--bare", "testdata/src", dir).Run()
+		CheckTestInitError(err)
+	}
+	// stale/IsStale on mirror2 diverges so moving IsStale fails
+	err := exec.Command("git", "-C", "testdata/mirror2", "branch", "stale/IsStale", "IsFresh").Run()
+	CheckTestInitError(err)
+
+	t.Setenv("GROOMBA_AUTH", "default")
+	t.Setenv("GROOMBA_CLOBBER", "false")
+	// empty variables are ignored so the manifest config applies
+	t.Setenv("GROOMBA_DRY_RUN", "")
+	t.Setenv("GROOMBA_PREFIX", "stale/")
+	t.Setenv("GROOMBA_REMOTE", "origin")
+	t.Setenv("GROOMBA_REMOTES", "")
+	mirror, _ := filepath.Abs("testdata/mirror")
+	mirror2, _ := filepath.Abs("testdata/mirror2")
+	dst, _ := filepath.Abs("testdata/dst")
+	m := &Manifest{MaxConcurrency: 2, Repositories: []ManifestRepository{
+		{Name: "mirror", URL: mirror},
+		{Name: "dst", Path: dst, Config: map[string]interface{}{"dry_run": true}},
+		{Name: "mirror2", URL: mirror2},
+		{Name: "missing", URL: mirror + ".missing"},
+	}}
+
+	report, err := m.RunAll(time.Now())
+	stale := []string{"origin/IsStale", "origin/IsStale2"}
+	t.Run("report should list every repository in manifest order", func(t *testing.T) {
+		a := assert.New(t)
+		a.Equal(4, len(report.Repositories))
+		a.Equal(RepositoryReport{Name: "mirror", GroomReport: GroomReport{Moved: stale}}, report.Repositories[0])
+		a.Equal(RepositoryReport{Name: "dst", DryRun: true, GroomReport: GroomReport{Moved: stale}}, report.Repositories[1])
+		a.Equal("mirror2", report.Repositories[2].Name)
+		a.Equal([]string{"origin/IsStale2"}, report.Repositories[2].Moved, "branches that failed to move should not be reported")
+		a.Equal("branch: IsStale failed on operation copy with error: non-fast-forward update: refs/heads/stale/IsStale", report.Repositories[2].Error)
+		a.Equal("missing", report.Repositories[3].Name)
+		a.Contains(report.Repositories[3].Error, "failed to fetch references from upstream")
+	})
+
+	t.Run("combined error should keep the errors of each repository", func(t *testing.T) {
+		a := assert.New(t)
+		var r *RunAllError
+		a.True(errors.As(err, &r))
+		if r != nil {
+			a.Equal(2, len(r.errList))
+		}
+		var moveErr *MoveStaleBranchesError
+		a.True(errors.As(err, &moveErr), "MoveStaleBranchesError should be found in the combined error")
+		if moveErr != nil {
+			a.Equal(1, len(moveErr.errList))
+		}
+		a.Contains(err.Error(), "repository: mirror2 failed with error: branch: IsStale failed on operation copy")
+		a.Contains(err.Error(), "repository: missing failed with error:")
+	})
+
+	t.Run("stale branches should only be moved without dry_run", func(t *testing.T) {
+		a := assert.New(t)
+		repo, _ := git.PlainOpen("testdata/mirror")
+		_, err := repo.Reference("refs/heads/stale/IsStale", false)
+		a.Nil(err)
+		_, err = repo.Reference("refs/heads/IsStale", false)
+		a.NotNil(err)
+
+		repo, _ = git.PlainOpen("testdata/src")
+		_, err = repo.Reference("refs/heads/IsStale", false)
+		a.Nil(err)
+	})
+
+	t.Run("repositories should be opened with the config loader", func(t *testing.T) {
+		a := assert.New(t)
+		file := filepath.Join(t.TempDir(), "groomba.yaml")
+		a.Nil(os.WriteFile(file, []byte("dry_run: true\n"), 0644))
+		l := NewConfigLoader()
+		l.SetConfigFile(file)
+		report, err := l.RunAll(&Manifest{MaxConcurrency: 1, Repositories: []ManifestRepository{{Name: "dst", Path: dst}}}, time.Now())
+		a.Nil(err)
+		a.Equal([]RepositoryReport{{Name: "dst", DryRun: true, GroomReport: GroomReport{Moved: stale}}}, report.Repositories)
+	})
+}
@@ -0,0 +1,129 @@
+package groomba
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// OpenPath loads the config of the repository at path, opens it and fetches
+// the latest references of its remotes
+func OpenPath(path string) (Groomba, error) {
+	return defaultLoader.OpenPath(path)
+}
+
+// OpenURL fetches the branches of the repository at url into an in-memory
+// repository and loads the config from its default branch, nothing is written
+// to disk. The remote is named after Remote
+func OpenURL(url string) (Groomba, error) {
+	return defaultLoader.OpenURL(url)
+}
+
+// OpenPath is like the package level OpenPath with the config loaded by l
+func (l *ConfigLoader) OpenPath(path string) (Groomba, error) {
+	return l.openPath(path, nil)
+}
+
+// OpenURL is like the package level OpenURL with the config loaded by l
+func (l *ConfigLoader) OpenURL(url string) (Groomba, error) {
+	return l.openURL(url, nil)
+}
+
+// openPath is OpenPath with overrides applied on top of the config files.
+// With RemoteConfig the config is reloaded from the default branch of Remote
+// once it is fetched
+func (l *ConfigLoader) openPath(path string, overrides map[string]interface{}) (Groomba, error) {
+	cfg, err := l.fromDir(path, overrides)
+	if err != nil {
+		return Groomba{}, fmt.Errorf("failed to get configs: %w", err)
+	}
+
+	repo, err := PlainOpen(path)
+	if err != nil {
+		return Groomba{}, fmt.Errorf("failed to open repository: %w", err)
+	}
+	g, err := fetch(cfg, repo)
+	if err != nil || !cfg.RemoteConfig {
+		return g, err
+	}
+
+	cfg, err = l.remoteConfig(g, overrides)
+	if err != nil {
+		return Groomba{}, err
+	}
+	return fetch(cfg, repo)
+}
+
+// openURL is OpenURL with overrides applied on top of the config files
+func (l *ConfigLoader) openURL(url string, overrides map[string]interface{}) (Groomba, error) {
+	// the config of the repository is not known until its branches are fetched
+	cfg, err := l.fromTree(nil, overrides)
+	if err != nil {
+		return Groomba{}, fmt.Errorf("failed to get configs: %w", err)
+	}
+
+	remote := cfg.Remote
+	repo, err := NewMemoryRepository(remote, url)
+	if err != nil {
+		return Groomba{}, fmt.Errorf("failed to create in-memory repository: %w", err)
+	}
+	g, err := fetch(cfg, repo)
+	if err != nil {
+		return Groomba{}, err
+	}
+
+	cfg, err = l.remoteConfig(g, overrides)
+	if err != nil {
+		return Groomba{}, err
+	}
+	// the in-memory repository only has the remote for url
+	cfg.Remote, cfg.Remotes = remote, nil
+	return fetch(cfg, repo)
+}
+
+// remoteConfig loads the config with the .groomba config file read from the
+// tip commit of the default branch of Remote fetched by g instead of the
+// config files of a checkout, so the owners of the repository control its
+// config through the history of the default branch
+func (l *ConfigLoader) remoteConfig(g Groomba, overrides map[string]interface{}) (*Config, error) {
+	tree, err := g.DefaultBranchTree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default branch: %w", err)
+	}
+	cfg, err := l.fromTree(tree, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configs from default branch: %w", err)
+	}
+	return cfg, nil
+}
+
+// fetch initializes auth for cfg and fetches the latest references of repo
+// from the configured remotes
+func fetch(cfg *Config, repo *git.Repository) (Groomba, error) {
+	a, err := cfg.Resolver()
+	if err != nil {
+		return Groomba{}, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+
+	g := NewGroomba(cfg, repo, a)
+	if err := g.Fetch(); err != nil {
+		return Groomba{}, fmt.Errorf("failed to fetch references from upstream: %w", err)
+	}
+	return g, nil
+}
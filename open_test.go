@@ -0,0 +1,103 @@
+package groomba
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenPathRemoteConfig(t *testing.T) {
+	InitTest()
+
+	// the config committed after cloning is only on the default branch of the
+	// remote, the checkout has a different untracked config file
+	err := os.WriteFile("testdata/src/.groomba.yaml", []byte("prefix: remote/\ndelete_age_threshold: 30\n"), 0644)
+	assert.Nil(t, err)
+	for _, args := range [][]string{{"add", ".groomba.yaml"}, {"commit", "-m", "Add_config"}} {
+		err = exec.Command("git", append([]string{"-C", "testdata/src"}, args...)...).Run()
+		assert.Nil(t, err)
+	}
+	err = os.WriteFile("testdata/dst/.groomba.yaml", []byte("prefix: local/\n"), 0644)
+	assert.Nil(t, err)
+
+	t.Setenv("XDG_CONFIG_DIRS", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GROOMBA_AUTH", "default")
+	t.Setenv("GROOMBA_CONFIG", "")
+	t.Setenv("GROOMBA_DELETE_AGE_THRESHOLD", "")
+	t.Setenv("GROOMBA_PREFIX", "")
+	t.Setenv("GROOMBA_REMOTE", "origin")
+	t.Setenv("GROOMBA_REMOTES", "")
+
+	t.Run("config should be read from the checkout by default", func(t *testing.T) {
+		a := assert.New(t)
+		g, err := OpenPath("testdata/dst")
+		if a.Nil(err) {
+			a.Equal("local/", g.Config().Prefix)
+			a.Equal(0, g.Config().DeleteAgeThreshold)
+		}
+	})
+
+	t.Run("config should be read from the default branch with remote_config", func(t *testing.T) {
+		a := assert.New(t)
+		t.Setenv("GROOMBA_REMOTE_CONFIG", "true")
+		g, err := OpenPath("testdata/dst")
+		if a.Nil(err) {
+			a.Equal("remote/", g.Config().Prefix)
+			a.Equal(30, g.Config().DeleteAgeThreshold)
+			a.Equal(".groomba.yaml (default branch)", g.Config().Origin("prefix"))
+		}
+	})
+
+	t.Run("default branch config should only set policy keys", func(t *testing.T) {
+		a := assert.New(t)
+		err := os.WriteFile("testdata/src/.groomba.yaml", []byte("prefix: remote/\nproxy_url: http://proxy.example.com\nauth: token\ntoken_env: LEAK\n"), 0644)
+		a.Nil(err)
+		err = exec.Command("git", "-C", "testdata/src", "commit", "-am", "Change_config").Run()
+		a.Nil(err)
+		t.Setenv("GROOMBA_REMOTE_CONFIG", "true")
+
+		_, err = OpenPath("testdata/dst")
+		var validationErr *ConfigValidationError
+		if a.True(errors.As(err, &validationErr)) {
+			a.Equal(3, len(validationErr.Problems()))
+			for _, key := range []string{"auth", "proxy_url", "token_env"} {
+				a.Contains(err.Error(), "config key "+key+" in .groomba.yaml (default branch) can't be set from the default branch")
+			}
+			a.NotContains(err.Error(), "prefix")
+		}
+	})
+
+	t.Run("default branch config should only turn on dry_run and turn off clobber", func(t *testing.T) {
+		a := assert.New(t)
+		t.Setenv("GROOMBA_CLOBBER", "")
+		t.Setenv("GROOMBA_DRY_RUN", "")
+		t.Setenv("GROOMBA_REMOTE_CONFIG", "true")
+		commitConfig := func(config string) {
+			err := os.WriteFile("testdata/src/.groomba.yaml", []byte(config), 0644)
+			a.Nil(err)
+			err = exec.Command("git", "-C", "testdata/src", "commit", "-am", "Change_config").Run()
+			a.Nil(err)
+		}
+
+		commitConfig("dry_run: false\nclobber: true\n")
+		_, err := OpenPath("testdata/dst")
+		var validationErr *ConfigValidationError
+		if a.True(errors.As(err, &validationErr)) {
+			a.Equal([]string{
+				"config key clobber in .groomba.yaml (default branch) can only be set to false from the default branch",
+				"config key dry_run in .groomba.yaml (default branch) can only be set to true from the default branch",
+			}, validationErr.Problems())
+		}
+
+		commitConfig("dry_run: true\nclobber: false\n")
+		g, err := OpenPath("testdata/dst")
+		if a.Nil(err) {
+			a.True(g.Config().DryRun)
+			a.False(g.Config().Clobber)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package groomba
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// isRegexPattern returns true if pattern is a regular expression in the form
+// /regex/ rather than a glob
+func isRegexPattern(pattern string) bool {
+	return len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/")
+}
+
+// compileRegexPattern compiles a /regex/ pattern so it has to match the whole
+// branch name
+func compileRegexPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern[1:len(pattern)-1]))
+}
+
+// pattern is a compiled glob or /regex/ pattern
+type pattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// compilePattern returns the compiled pattern or an error if pattern is
+// neither a valid glob nor a valid /regex/ pattern
+func compilePattern(p string) (pattern, error) {
+	if isRegexPattern(p) {
+		re, err := compileRegexPattern(p)
+		if err != nil {
+			return pattern{}, fmt.Errorf("invalid regex pattern %s: %s", p, err)
+		}
+		return pattern{re: re}, nil
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return pattern{}, fmt.Errorf("invalid glob pattern %s: %s", p, err)
+	}
+	return pattern{glob: p}, nil
+}
+
+// match returns true if branch matches p. Patterns in the form /regex/ are
+// regular expressions matching the whole branch name, all other patterns are
+// globs where * does not match /, a pattern without any special characters
+// only matches the exact branch name
+func (p pattern) match(branch string) bool {
+	if p.re != nil {
+		return p.re.MatchString(branch)
+	}
+	matched, err := path.Match(p.glob, branch)
+	return err == nil && matched
+}
+
+// matchAnyPattern returns true if branch matches any of patterns. Patterns
+// compiled by Validate are reused, others are compiled on every call and
+// never match if they are invalid
+func (c *Config) matchAnyPattern(patterns []string, branch string) bool {
+	for _, p := range patterns {
+		compiled, ok := c.patterns[p]
+		if !ok {
+			var err error
+			if compiled, err = compilePattern(p); err != nil {
+				continue
+			}
+		}
+		if compiled.match(branch) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package groomba
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		branch  string
+		match   bool
+	}{
+		{"main", "main", true},
+		{"main", "main2", false},
+		{"release/*", "release/1.2", true},
+		{"release/*", "release/1.2/fix", false},
+		{"release/*", "release", false},
+		{"hotfix-*", "hotfix-123", true},
+		{"hotfix-*", "feature/hotfix-123", false},
+		{"release-1.?", "release-1.3", true},
+		{"/release-\\d+\\.\\d+/", "release-1.23", true},
+		{"/release-\\d+\\.\\d+/", "release-1.x", false},
+		{"/release-\\d+/", "old-release-1", false},
+		{"/(main|develop)/", "develop", true},
+		{"//", "//", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.branch, func(t *testing.T) {
+			p, err := compilePattern(tt.pattern)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.match, p.match(tt.branch))
+		})
+	}
+}
+
+func TestConfigMatchAnyPattern(t *testing.T) {
+	a := assert.New(t)
+	c := &Config{StaticBranches: []string{"main", "/revert.*/"}, ExcludePatterns: []string{"release-[1"}}
+	a.NotNil(c.Validate())
+	a.Equal(2, len(c.patterns), "valid patterns should be compiled by Validate")
+	a.NotNil(c.patterns["/revert.*/"].re)
+
+	a.True(c.matchAnyPattern(c.StaticBranches, "revert-foo"))
+	a.True(c.matchAnyPattern(c.StaticBranches, "main"))
+	a.False(c.matchAnyPattern(c.StaticBranches, "feature"))
+	a.False(c.matchAnyPattern(c.ExcludePatterns, "release-1"), "invalid patterns should never match")
+	a.True(c.matchAnyPattern([]string{"feat*"}, "feature"), "patterns set after Validate should be compiled when matched")
+}
+
+func TestCompilePattern(t *testing.T) {
+	t.Run("valid patterns should not return errors", func(t *testing.T) {
+		a := assert.New(t)
+		_, err := compilePattern("main")
+		a.Nil(err)
+		_, err = compilePattern("release/*")
+		a.Nil(err)
+		_, err = compilePattern("/release-\\d+/")
+		a.Nil(err)
+	})
+	t.Run("invalid glob should return error", func(t *testing.T) {
+		_, err := compilePattern("release-[1")
+		assert.EqualError(t, err, "invalid glob pattern release-[1: syntax error in pattern")
+	})
+	t.Run("invalid regex should return error", func(t *testing.T) {
+		_, err := compilePattern("/release-(/")
+		assert.EqualError(t, err, "invalid regex pattern /release-(/: error parsing regexp: missing closing ): `^(?:release-()$`")
+	})
+}
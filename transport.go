@@ -0,0 +1,82 @@
+package groomba
+
+/*
+   Copyright 2021 Amod Mulay
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// remoteOptions are the settings used to connect to a remote
+type remoteOptions struct {
+	url             string
+	auth            transport.AuthMethod
+	caBundle        []byte
+	insecureSkipTLS bool
+	proxy           transport.ProxyOptions
+}
+
+// remoteOptions returns the url of Remote together with the auth method the
+// Authenticator picked for it and the TLS and proxy settings to use
+func (g Groomba) remoteOptions() (remoteOptions, error) {
+	r, err := g.repo.Remote(g.cfg.Remote)
+	if err != nil {
+		return remoteOptions{}, err
+	}
+	ro := remoteOptions{
+		caBundle:        g.cfg.caBundle,
+		insecureSkipTLS: g.cfg.InsecureSkipTLSVerify,
+	}
+	if urls := r.Config().URLs; len(urls) > 0 {
+		ro.url = urls[0]
+	}
+	ro.proxy = g.cfg.proxyFor(ro.url)
+	ro.auth, err = g.auth.Get(ro.url)
+	return ro, err
+}
+
+// proxyFor returns the proxy options for the remote rawURL. ProxyURL is only
+// used for http and https remotes whose host does not match NoProxy, or the
+// NO_PROXY environment variable if NoProxy is not set. Without ProxyURL the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply as usual
+func (c *Config) proxyFor(rawURL string) transport.ProxyOptions {
+	if c.ProxyURL == "" {
+		return transport.ProxyOptions{}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return transport.ProxyOptions{}
+	}
+	noProxy := strings.Join(c.NoProxy, ",")
+	if len(c.NoProxy) == 0 {
+		noProxy = httpproxy.FromEnvironment().NoProxy
+	}
+	cfg := httpproxy.Config{HTTPProxy: c.ProxyURL, HTTPSProxy: c.ProxyURL, NoProxy: noProxy}
+	proxy, err := cfg.ProxyFunc()(u)
+	if err != nil {
+		log.Warnf("ignoring invalid proxy_url: %s", err)
+		return transport.ProxyOptions{}
+	}
+	if proxy == nil {
+		return transport.ProxyOptions{}
+	}
+	return transport.ProxyOptions{URL: proxy.String()}
+}
@@ -0,0 +1,74 @@
+package groomba
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyFor(t *testing.T) {
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+	for _, tc := range []struct {
+		name     string
+		proxyURL string
+		noProxy  []string
+		env      string
+		url      string
+		expected string
+	}{
+		{"no proxy_url should use no proxy", "", nil, "", "https://github.com/avbm/groomba.git", ""},
+		{"https remote should use proxy_url", "http://proxy.example.com:3128", nil, "", "https://github.com/avbm/groomba.git", "http://proxy.example.com:3128"},
+		{"http remote should use proxy_url", "http://proxy.example.com:3128", nil, "", "http://git.example.com/avbm/groomba.git", "http://proxy.example.com:3128"},
+		{"ssh remote should not use proxy_url", "http://proxy.example.com:3128", nil, "", "git@github.com:avbm/groomba.git", ""},
+		{"local remote should not use proxy_url", "http://proxy.example.com:3128", nil, "", "/tmp/groomba", ""},
+		{"no_proxy host should not use proxy_url", "http://proxy.example.com:3128", []string{"github.com"}, "", "https://github.com/avbm/groomba.git", ""},
+		{"no_proxy domain should not use proxy_url", "http://proxy.example.com:3128", []string{"internal", ".example.com"}, "", "https://git.example.com/avbm/groomba.git", ""},
+		{"other hosts should use proxy_url with no_proxy", "http://proxy.example.com:3128", []string{"github.com"}, "", "https://gitlab.com/avbm/groomba.git", "http://proxy.example.com:3128"},
+		{"NO_PROXY should be used if no_proxy is not set", "http://proxy.example.com:3128", nil, "github.com", "https://github.com/avbm/groomba.git", ""},
+		{"no_proxy should override NO_PROXY", "http://proxy.example.com:3128", []string{"gitlab.com"}, "github.com", "https://github.com/avbm/groomba.git", "http://proxy.example.com:3128"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("NO_PROXY", tc.env)
+			cfg := &Config{ProxyURL: tc.proxyURL, NoProxy: tc.noProxy}
+			assert.Equal(t, transport.ProxyOptions{URL: tc.expected}, cfg.proxyFor(tc.url))
+		})
+	}
+}
+
+func TestRemoteOptions(t *testing.T) {
+	InitTest()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, []byte("-----BEGIN CERTIFICATE-----\n"), 0600)
+	assert.Nil(t, err)
+	t.Setenv("GROOMBA_REMOTE", "origin")
+	t.Setenv("GROOMBA_CA_BUNDLE_FILE", caFile)
+	t.Setenv("GROOMBA_INSECURE_SKIP_TLS_VERIFY", "true")
+	t.Setenv("GROOMBA_PROXY_URL", "http://proxy.example.com:3128")
+	cfg, err := GetConfig(".")
+	assert.Nil(t, err)
+	repo, _ := git.PlainOpen("testdata/dst")
+	g := Groomba{cfg: cfg, repo: repo, auth: &MockAuthenticator{}}
+
+	t.Run("TLS settings should be passed to the transport", func(t *testing.T) {
+		a := assert.New(t)
+		ro, err := g.remoteOptions()
+		a.Nil(err)
+		src, _ := filepath.Abs("testdata/src")
+		a.Equal(src, ro.url)
+		a.Equal([]byte("-----BEGIN CERTIFICATE-----\n"), ro.caBundle)
+		a.True(ro.insecureSkipTLS)
+		a.Equal(transport.ProxyOptions{}, ro.proxy, "local remotes should not use the proxy")
+	})
+
+	t.Run("missing ca_bundle_file should return error", func(t *testing.T) {
+		t.Setenv("GROOMBA_CA_BUNDLE_FILE", caFile+".missing")
+		_, err := GetConfig(".")
+		assert.EqualError(t, err, "getConfig: failed to read ca_bundle_file: open "+caFile+".missing: no such file or directory")
+	})
+}